@@ -0,0 +1,132 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package agent implements the remote benchmark agent: a small gRPC service
+// which executes benchmark runs on behalf of a coordinator, so the client
+// load and the daemon under test can live on separate hosts and a single
+// invocation can drive several machines at once.
+//
+// The service descriptor is written by hand and the messages travel as JSON
+// rather than protobuf; the payloads are this repository's own typed structs
+// (bench.Options in, bench.Result out) so no generated code or protoc step
+// is needed.
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/estesp/bucketbench/bench"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+const (
+	serviceName = "bucketbench.Agent"
+	runMethod   = "/bucketbench.Agent/Run"
+)
+
+// jsonCodec marshals gRPC messages as JSON so plain Go structs can cross the
+// wire without protobuf definitions
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                       { return "json" }
+
+// runRequest asks the agent to execute one benchmark run
+type runRequest struct {
+	Options bench.Options `json:"options"`
+}
+
+// runResponse carries the collected result of a completed run
+type runResponse struct {
+	Result bench.Result `json:"result"`
+}
+
+// agentService is the server-side contract backing the hand-written service
+// descriptor
+type agentService interface {
+	Run(ctx context.Context, req *runRequest) (*runResponse, error)
+}
+
+type server struct{}
+
+func (s *server) Run(ctx context.Context, req *runRequest) (*runResponse, error) {
+	// validate here as well as in the client: the agent is a network
+	// service and must not trust the peer to have screened the options
+	if req.Options.Legacy {
+		return nil, fmt.Errorf("legacy thread sweeps are not supported with remote agents")
+	}
+	if req.Options.Driver.Threads < 1 {
+		return nil, fmt.Errorf("no threads configured for agent run")
+	}
+	log.Infof("agent: starting %s benchmark against driver %q (%d threads, %d iterations)",
+		req.Options.BenchType, req.Options.Driver.Type, req.Options.Driver.Threads, req.Options.Driver.Iterations)
+	result, err := bench.Run(ctx, req.Options)
+	if err != nil {
+		log.Errorf("agent: benchmark run failed: %v", err)
+		return nil, err
+	}
+	log.Infof("agent: benchmark run complete (rate %6.2f)", result.ThreadRates[0])
+	return &runResponse{Result: result}, nil
+}
+
+func runHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	req := new(runRequest)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(agentService).Run(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: runMethod,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(agentService).Run(ctx, req.(*runRequest))
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*agentService)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Run",
+			Handler:    runHandler,
+		},
+	},
+}
+
+// Serve listens on the given address and handles benchmark run requests until
+// the context is cancelled
+func Serve(ctx context.Context, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	grpcServer := grpc.NewServer(grpc.ForceServerCodec(jsonCodec{}))
+	grpcServer.RegisterService(&serviceDesc, &server{})
+	go func() {
+		<-ctx.Done()
+		grpcServer.GracefulStop()
+	}()
+	log.Infof("bucketbench agent listening on %s", listener.Addr())
+	return grpcServer.Serve(listener)
+}
@@ -0,0 +1,153 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/estesp/bucketbench/bench"
+	"github.com/estesp/bucketbench/benches"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Client is a connection to one remote benchmark agent
+type Client struct {
+	address string
+	conn    *grpc.ClientConn
+}
+
+// NewClient dials the agent at the given address
+func NewClient(address string) (*Client, error) {
+	conn, err := grpc.Dial(address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})))
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't connect to agent %s", address)
+	}
+	return &Client{
+		address: address,
+		conn:    conn,
+	}, nil
+}
+
+// Run executes one benchmark run on the remote agent and returns its result
+func (c *Client) Run(ctx context.Context, opts bench.Options) (bench.Result, error) {
+	req := &runRequest{Options: opts}
+	resp := new(runResponse)
+	if err := c.conn.Invoke(ctx, runMethod, req, resp); err != nil {
+		return bench.Result{}, errors.Wrapf(err, "benchmark run on agent %s failed", c.address)
+	}
+	return resp.Result, nil
+}
+
+// Close tears down the agent connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// RunDistributed fans one benchmark run out across the given agents, dividing
+// the configured thread count between them, and merges the per-agent results
+// into a single cluster-wide result
+func RunDistributed(ctx context.Context, agents []string, opts bench.Options) (bench.Result, error) {
+	if opts.Legacy {
+		return bench.Result{}, fmt.Errorf("legacy thread sweeps are not supported with remote agents")
+	}
+	if opts.Driver.Threads < 1 {
+		return bench.Result{}, fmt.Errorf("no threads configured for distributed run")
+	}
+
+	// divide the threads as evenly as possible; with fewer threads than
+	// agents the extra agents are simply left idle
+	shares := make([]int, 0, len(agents))
+	addresses := make([]string, 0, len(agents))
+	per, extra := opts.Driver.Threads/len(agents), opts.Driver.Threads%len(agents)
+	for i, address := range agents {
+		share := per
+		if i < extra {
+			share++
+		}
+		if share == 0 {
+			continue
+		}
+		shares = append(shares, share)
+		addresses = append(addresses, address)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		results  = make([]bench.Result, len(addresses))
+		firstErr error
+	)
+	for i := range addresses {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			agentOpts := opts
+			agentOpts.Driver.Threads = shares[idx]
+			client, err := NewClient(addresses[idx])
+			if err == nil {
+				defer client.Close()
+				results[idx], err = client.Run(ctx, agentOpts)
+			}
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return bench.Result{}, firstErr
+	}
+
+	return mergeResults(addresses, results, opts), nil
+}
+
+// mergeResults folds the per-agent results into one: rates are summed into a
+// cluster rate, raw statistics are concatenated, and bounded-memory summaries
+// are combined command by command
+func mergeResults(addresses []string, results []bench.Result, opts bench.Options) bench.Result {
+	merged := bench.Result{
+		Name:       results[0].Name,
+		DriverInfo: fmt.Sprintf("%s [%d agents]", results[0].DriverInfo, len(addresses)),
+		Threads:    opts.Driver.Threads,
+		Iterations: opts.Driver.Iterations,
+	}
+
+	var (
+		clusterRate float64
+		allStats    []benches.RunStatistics
+		summary     *benches.RunSummary
+	)
+	for _, result := range results {
+		clusterRate += result.ThreadRates[0]
+		allStats = append(allStats, result.Statistics[0]...)
+		if len(result.Summaries) > 0 && result.Summaries[0] != nil {
+			summary = benches.MergeSummaries(summary, result.Summaries[0])
+		}
+	}
+	merged.ThreadRates = []float64{clusterRate}
+	merged.Statistics = [][]benches.RunStatistics{allStats}
+	merged.Summaries = []*benches.RunSummary{summary}
+	return merged
+}
@@ -0,0 +1,160 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/estesp/bucketbench/bench"
+	"github.com/estesp/bucketbench/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// sshRemoteDir is where the benchmark YAML and result files are staged on
+// each remote host
+const sshRemoteDir = "/tmp"
+
+// ReadInventory parses an inventory file of SSH destinations, one per line
+// in "host" or "user@host" form; blank lines and '#' comments are skipped
+func ReadInventory(filename string) ([]string, error) {
+	inventory, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read inventory file %q", filename)
+	}
+	defer inventory.Close()
+
+	var hosts []string
+	scanner := bufio.NewScanner(inventory)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hosts = append(hosts, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("inventory file %q lists no hosts", filename)
+	}
+	return hosts, nil
+}
+
+// RunSSH is the simpler multi-host alternative to the gRPC agent: the
+// benchmark YAML is copied to every host in the inventory, bucketbench is
+// run there over SSH (it must be installed on the remote PATH), and the
+// JSON results are collected and returned with each result name qualified
+// by its host for a combined comparison report
+func RunSSH(ctx context.Context, inventoryFile, benchmarkFile string, extraArgs []string) ([]bench.Result, error) {
+	hosts, err := ReadInventory(inventoryFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wg          sync.WaitGroup
+		mu          sync.Mutex
+		hostResults = make([][]bench.Result, len(hosts))
+		firstErr    error
+	)
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(idx int, host string) {
+			defer wg.Done()
+			results, err := runOnHost(ctx, host, benchmarkFile, extraArgs)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+			hostResults[idx] = results
+		}(i, host)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// keep inventory order in the combined report
+	var combined []bench.Result
+	for _, results := range hostResults {
+		combined = append(combined, results...)
+	}
+	return combined, nil
+}
+
+// runOnHost copies the benchmark definition to one host, runs bucketbench
+// there, and parses the collected JSON results
+func runOnHost(ctx context.Context, host, benchmarkFile string, extraArgs []string) ([]bench.Result, error) {
+	remoteYaml := filepath.Join(sshRemoteDir, "bucketbench-"+filepath.Base(benchmarkFile))
+	remoteResults := filepath.Join(sshRemoteDir, "bucketbench-results.json")
+
+	log.Infof("%s: copying benchmark definition", host)
+	if out, err := utils.ExecCmd(ctx, "scp", fmt.Sprintf("%s %s:%s", benchmarkFile, host, remoteYaml)); err != nil {
+		return nil, errors.Wrapf(err, "can't copy benchmark YAML to %s: %s", host, out)
+	}
+
+	remoteCmd := fmt.Sprintf("bucketbench run -b %s --skip-limit --results-json %s", remoteYaml, remoteResults)
+	if len(extraArgs) > 0 {
+		remoteCmd = fmt.Sprintf("%s %s", remoteCmd, strings.Join(extraArgs, " "))
+	}
+	log.Infof("%s: running benchmark", host)
+	if out, err := utils.ExecCmd(ctx, "ssh", fmt.Sprintf("%s %s", host, remoteCmd)); err != nil {
+		return nil, errors.Wrapf(err, "benchmark run on %s failed: %s", host, out)
+	}
+
+	localResults, err := os.CreateTemp("", "bucketbench-results-*.json")
+	if err != nil {
+		return nil, err
+	}
+	localResults.Close()
+	defer os.Remove(localResults.Name())
+
+	if out, err := utils.ExecCmd(ctx, "scp", fmt.Sprintf("%s:%s %s", host, remoteResults, localResults.Name())); err != nil {
+		return nil, errors.Wrapf(err, "can't collect results from %s: %s", host, out)
+	}
+
+	data, err := os.ReadFile(localResults.Name())
+	if err != nil {
+		return nil, err
+	}
+	var results []bench.Result
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, errors.Wrapf(err, "can't parse results from %s", host)
+	}
+
+	// qualify each result with its host (sans any user@ prefix) so the
+	// combined report distinguishes where each run executed
+	shortHost := host
+	if at := strings.Index(host, "@"); at >= 0 {
+		shortHost = host[at+1:]
+	}
+	for i := range results {
+		results[i].Name = fmt.Sprintf("%s@%s", results[i].Name, shortHost)
+	}
+	return results, nil
+}
@@ -0,0 +1,278 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bench exposes bucketbench's benchmark execution as an importable
+// library, so other Go programs and tests can run benchmarks and consume
+// typed results without shelling out to the CLI.
+package bench
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/estesp/bucketbench/benches"
+	"github.com/estesp/bucketbench/driver"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// LimitBenchmarkName names the synthetic thread-limit benchmark in results
+const LimitBenchmarkName = "Limit"
+
+// Options configures one benchmark execution against a single driver entry
+// of a benchmark definition
+type Options struct {
+	// BenchType selects the benchmark style (Custom, Overhead, Density,
+	// Restart)
+	BenchType benches.Type
+	// Driver is the driver entry (type, threads, iterations, tuning) the
+	// benchmark runs against
+	Driver benches.DriverConfig
+	// Benchmark is the benchmark definition (image, commands, assertions)
+	Benchmark benches.Benchmark
+	// Trace enables per-container tracing where the runtime supports it
+	Trace bool
+	// Legacy sweeps thread counts from 1 to Driver.Threads with one run
+	// each, instead of a single run at the configured thread count
+	Legacy bool
+}
+
+// Result collects the rates and statistics of one benchmark execution; for
+// legacy sweeps the per-run slices hold one entry per thread count
+type Result struct {
+	Name        string
+	DriverInfo  string
+	Threads     int
+	Iterations  int
+	ThreadRates []float64
+	Statistics  [][]benches.RunStatistics
+	// Summaries holds the bounded-memory aggregation per run when the
+	// benchmark streamed its statistics instead of retaining them
+	Summaries []*benches.RunSummary
+	// Environment records the host the result was taken on, so persisted
+	// results remain interpretable away from the machine that produced them
+	Environment *Environment
+	// ImageDigest is the digest the benchmark image reference resolved to,
+	// when the driver reports it; relevant for multi-arch references where
+	// the same tag measures different images on different hosts
+	ImageDigest string
+}
+
+// singleResult carries the output of one benchmark run, including the
+// driver so a thread sweep can reuse the connection for its next run
+type singleResult struct {
+	benchInfo   string
+	driverInfo  string
+	imageDigest string
+	threadRate  float64
+	statistic   []benches.RunStatistics
+	summary     *benches.RunSummary
+	drv         driver.Driver
+}
+
+// driverReuser is implemented by benchmarks which can hand their initialized
+// driver to a subsequent benchmark instance, avoiding repeated client dials
+// and environment cleanups during the legacy 1..N thread sweep
+type driverReuser interface {
+	Driver() driver.Driver
+	ReuseDriver(driver.Driver)
+}
+
+// Run executes a benchmark according to the provided options and returns
+// its collected rates and statistics
+func Run(ctx context.Context, opts Options) (Result, error) {
+	var (
+		rates       []float64
+		stats       [][]benches.RunStatistics
+		summaries   []*benches.RunSummary
+		benchInfo   string
+		driverInfo  string
+		imageDigest string
+	)
+
+	if opts.Legacy {
+		stats = make([][]benches.RunStatistics, opts.Driver.Threads)
+		summaries = make([]*benches.RunSummary, opts.Driver.Threads)
+		// Legacy mode in total run N test suites. for each test suite, it runs with n thread and n is the current thread numbers.
+		// the driver connection is carried from one suite to the next so the
+		// sweep doesn't pay a client dial, info query, and full cleanup per
+		// thread count
+		var sweepDriver driver.Driver
+		for i := 1; i <= opts.Driver.Threads; i++ {
+			single, err := runOnce(ctx, opts, i, sweepDriver)
+			if err != nil {
+				return Result{}, err
+			}
+			benchInfo, driverInfo, imageDigest = single.benchInfo, single.driverInfo, single.imageDigest
+			rates = append(rates, single.threadRate)
+			stats[i-1] = single.statistic
+			summaries[i-1] = single.summary
+			sweepDriver = single.drv
+		}
+	} else {
+		stats = make([][]benches.RunStatistics, 1)
+		summaries = make([]*benches.RunSummary, 1)
+		single, err := runOnce(ctx, opts, opts.Driver.Threads, nil)
+		if err != nil {
+			return Result{}, err
+		}
+		benchInfo, driverInfo, imageDigest = single.benchInfo, single.driverInfo, single.imageDigest
+		rates = append(rates, single.threadRate)
+		stats[0] = single.statistic
+		summaries[0] = single.summary
+	}
+
+	result := Result{
+		Name:        benchInfo,
+		DriverInfo:  driverInfo,
+		Threads:     opts.Driver.Threads,
+		Iterations:  opts.Driver.Iterations,
+		ThreadRates: rates,
+		Statistics:  stats,
+		Summaries:   summaries,
+		Environment: CaptureEnvironment(),
+		ImageDigest: imageDigest,
+	}
+
+	return result, nil
+}
+
+// runOnce executes exactly one benchmark run at the given thread count
+func runOnce(ctx context.Context, opts Options, threads int, reuse driver.Driver) (singleResult, error) {
+	driverConfig := opts.Driver
+	benchmark := opts.Benchmark
+	if len(benchmark.CustomMetrics) > 0 {
+		driverConfig.CustomMetrics = benchmark.CustomMetrics
+	}
+	if benchmark.ExecCommand != "" {
+		driverConfig.ExecCommand = benchmark.ExecCommand
+	}
+	if benchmark.ReadyCheck != nil {
+		driverConfig.ReadyCheck = benchmark.ReadyCheck
+	}
+	if benchmark.Expect != nil {
+		driverConfig.Expect = benchmark.Expect
+	}
+	if len(benchmark.Mounts) > 0 && len(driverConfig.Mounts) == 0 {
+		driverConfig.Mounts = benchmark.Mounts
+	}
+	if benchmark.PullPolicy != "" && driverConfig.PullPolicy == "" {
+		driverConfig.PullPolicy = benchmark.PullPolicy
+	}
+	if len(benchmark.Images) > 0 && len(driverConfig.Images) == 0 {
+		driverConfig.Images = benchmark.Images
+		if driverConfig.ImageOrder == "" {
+			driverConfig.ImageOrder = benchmark.ImageOrder
+		}
+	}
+	bench, err := benches.New(opts.BenchType, &driverConfig)
+	if err != nil {
+		return singleResult{}, err
+	}
+
+	driverType := driver.StringToType(driverConfig.Type)
+	// per-driver image/rootfs overrides allow a single benchmark to compare
+	// image variants, or engines with an image against runtimes with the
+	// equivalent exploded bundle
+	imageInfo := benchmark.Image
+	if driverConfig.Image != "" {
+		imageInfo = driverConfig.Image
+	}
+	if driverType == driver.Runc || driverType == driver.Ctr || driverType == driver.CRun || driverType == driver.Youki {
+		// legacy ctr mode, runc, crun and youki drivers need an exploded rootfs
+		// first, verify that a rootfs was provided in the benchmark YAML
+		rootFs := benchmark.RootFs
+		if driverConfig.RootFs != "" {
+			rootFs = driverConfig.RootFs
+		}
+		if rootFs == "" {
+			return singleResult{}, fmt.Errorf("no rootfs defined in the benchmark YAML; driver %s requires a root FS path", driverConfig.Type)
+		}
+
+		imageInfo = rootFs
+	}
+
+	if reuse != nil {
+		if reuser, ok := bench.(driverReuser); ok {
+			reuser.ReuseDriver(reuse)
+		}
+	}
+
+	err = bench.Init(ctx, benchmark.Name, driverType, driverConfig.ClientPath, imageInfo, benchmark.Command, opts.Trace)
+	if err != nil {
+		return singleResult{}, err
+	}
+
+	benchInfo := fmt.Sprintf("%s:%s", opts.BenchType, driverConfig.Type)
+
+	if err = bench.Validate(ctx); err != nil {
+		return singleResult{}, fmt.Errorf("error during bench validate: %v", err)
+	}
+
+	info, err := bench.Info(ctx)
+	if err != nil {
+		return singleResult{}, errors.Wrap(err, "failed to query driver info")
+	}
+
+	driverInfo := info
+
+	err = bench.Run(ctx, threads, driverConfig.Iterations, benchmark.Commands)
+	if err != nil {
+		return singleResult{}, fmt.Errorf("error during bench run: %v", err)
+	}
+
+	duration := bench.Elapsed()
+	statistics := bench.Stats()
+	summary := bench.Summary()
+	// count completed iterations from the collected statistics rather than
+	// assuming threads*iterations; for duration-based runs the total number
+	// of operations is an output of the run, not an input
+	completed := 0
+	if summary != nil {
+		// bounded-stats runs don't retain iteration entries; the
+		// aggregator carries the count instead
+		completed = summary.Iterations
+	} else {
+		for _, stat := range statistics {
+			if stat.Daemon == nil {
+				completed++
+			}
+		}
+	}
+	rate := float64(completed) / duration.Seconds()
+
+	result := singleResult{
+		driverInfo: driverInfo,
+		benchInfo:  benchInfo,
+		threadRate: rate,
+		statistic:  statistics,
+		summary:    summary,
+	}
+	if reuser, ok := bench.(driverReuser); ok {
+		result.drv = reuser.Driver()
+		if digester, ok := result.drv.(driver.ImageDigester); ok {
+			result.imageDigest = digester.ImageDigest()
+		}
+	}
+
+	log.Infof("%s: threads %d, iterations %d, rate: %6.2f", benchInfo, threads, completed/intMax(threads, 1), rate)
+	return result, nil
+}
+
+func intMax(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
@@ -0,0 +1,74 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/host"
+	"github.com/shirou/gopsutil/mem"
+)
+
+// Environment records the host a benchmark result was taken on; the numbers
+// are meaningless for later comparison without it, so it is captured
+// automatically and persisted alongside the results
+type Environment struct {
+	Hostname      string `json:"hostname"`
+	Kernel        string `json:"kernel"`
+	OS            string `json:"os"`
+	Arch          string `json:"arch"`
+	CPUModel      string `json:"cpuModel"`
+	CPUs          int    `json:"cpus"`
+	MemTotalBytes uint64 `json:"memTotalBytes"`
+	CgroupVersion string `json:"cgroupVersion"`
+}
+
+// CaptureEnvironment collects the current host details on a best-effort
+// basis; fields which can't be determined on this platform are left empty
+func CaptureEnvironment() *Environment {
+	env := &Environment{
+		OS:   runtime.GOOS,
+		Arch: runtime.GOARCH,
+		CPUs: runtime.NumCPU(),
+	}
+	if info, err := host.Info(); err == nil {
+		env.Hostname = info.Hostname
+		env.Kernel = info.KernelVersion
+	}
+	if infos, err := cpu.Info(); err == nil && len(infos) > 0 {
+		env.CPUModel = strings.TrimSpace(infos[0].ModelName)
+	}
+	if vmem, err := mem.VirtualMemory(); err == nil {
+		env.MemTotalBytes = vmem.Total
+	}
+	env.CgroupVersion = cgroupVersion()
+	return env
+}
+
+// cgroupVersion reports which cgroup hierarchy is mounted: "v2" for the
+// unified hierarchy, "v1" for the legacy per-controller mounts, empty when
+// neither is present (non-Linux hosts)
+func cgroupVersion() string {
+	if _, err := os.Stat("/sys/fs/cgroup/cgroup.controllers"); err == nil {
+		return "v2"
+	}
+	if _, err := os.Stat("/sys/fs/cgroup/memory"); err == nil {
+		return "v1"
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OverheadSample is one timestamped daemon resource sample in the exported
+// series; Thread is the run index the sample was collected during
+type OverheadSample struct {
+	Bench         string    `json:"bench"`
+	Thread        int       `json:"thread"`
+	Timestamp     time.Time `json:"timestamp"`
+	MemBytes      uint64    `json:"memBytes"`
+	CPUPercent    float64   `json:"cpuPercent"`
+	BlkReadBytes  uint64    `json:"blkReadBytes"`
+	BlkWriteBytes uint64    `json:"blkWriteBytes"`
+	NetRxBytes    uint64    `json:"netRxBytes"`
+	NetTxBytes    uint64    `json:"netTxBytes"`
+	FDs           uint64    `json:"fds"`
+	Threads       uint64    `json:"threads"`
+}
+
+// WriteResults exports the full typed results of a run as indented JSON, the
+// machine-readable counterpart to the tabular report, for external tooling
+// and multi-host result collection
+func WriteResults(filename string, results []Result) error {
+	outFile, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("can't create results file %q: %v", filename, err)
+	}
+	defer outFile.Close()
+	encoder := json.NewEncoder(outFile)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+// AppendResults appends one repetition's results to the named file as a
+// single JSON line wrapped with a timestamp and repetition counter, the
+// store format used by scheduled soak runs where each pass adds to the
+// history instead of replacing it
+func AppendResults(filename string, repetition int, results []Result) error {
+	outFile, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("can't open results store %q: %v", filename, err)
+	}
+	defer outFile.Close()
+	entry := struct {
+		Timestamp  time.Time `json:"timestamp"`
+		Repetition int       `json:"repetition"`
+		Results    []Result  `json:"results"`
+	}{
+		Timestamp:  time.Now(),
+		Repetition: repetition,
+		Results:    results,
+	}
+	return json.NewEncoder(outFile).Encode(entry)
+}
+
+// CollectOverheadSeries flattens the daemon samples from all benchmark runs
+// into one chronological-per-run series for export
+func CollectOverheadSeries(results []Result) []OverheadSample {
+	var series []OverheadSample
+	for _, result := range results {
+		if result.Name == LimitBenchmarkName {
+			continue
+		}
+		for threadIdx, statistics := range result.Statistics {
+			for _, stat := range statistics {
+				if stat.Daemon == nil {
+					continue
+				}
+				series = append(series, OverheadSample{
+					Bench:     result.Name,
+					Thread:    threadIdx + 1,
+					Timestamp: stat.Timestamp,
+					// the sampler stores memory in MiB; convert so the
+					// field really carries the bytes its name promises
+					MemBytes:      stat.Daemon.Mem * 1024 * 1024,
+					CPUPercent:    stat.Daemon.CPU,
+					BlkReadBytes:  stat.Daemon.BlkReadBytes,
+					BlkWriteBytes: stat.Daemon.BlkWriteBytes,
+					NetRxBytes:    stat.Daemon.NetRxBytes,
+					NetTxBytes:    stat.Daemon.NetTxBytes,
+					FDs:           stat.Daemon.FDs,
+					Threads:       stat.Daemon.Threads,
+				})
+			}
+		}
+	}
+	return series
+}
+
+// WriteOverheadSeries exports the full timestamped daemon sample series to
+// the named file so memory growth and CPU spikes can be plotted; the format
+// is JSON when the filename ends in .json and CSV otherwise
+func WriteOverheadSeries(filename string, results []Result) error {
+	series := CollectOverheadSeries(results)
+	if len(series) == 0 {
+		return fmt.Errorf("no overhead samples collected; was the run started with --overhead?")
+	}
+
+	outFile, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("can't create overhead series file %q: %v", filename, err)
+	}
+	defer outFile.Close()
+
+	if strings.HasSuffix(filename, ".json") {
+		encoder := json.NewEncoder(outFile)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(series)
+	}
+
+	writer := csv.NewWriter(outFile)
+	defer writer.Flush()
+	header := []string{"bench", "thread", "timestamp", "memBytes", "cpuPercent",
+		"blkReadBytes", "blkWriteBytes", "netRxBytes", "netTxBytes", "fds", "threads"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, sample := range series {
+		record := []string{
+			sample.Bench,
+			strconv.Itoa(sample.Thread),
+			sample.Timestamp.Format(time.RFC3339Nano),
+			strconv.FormatUint(sample.MemBytes, 10),
+			strconv.FormatFloat(sample.CPUPercent, 'f', 2, 64),
+			strconv.FormatUint(sample.BlkReadBytes, 10),
+			strconv.FormatUint(sample.BlkWriteBytes, 10),
+			strconv.FormatUint(sample.NetRxBytes, 10),
+			strconv.FormatUint(sample.NetTxBytes, 10),
+			strconv.FormatUint(sample.FDs, 10),
+			strconv.FormatUint(sample.Threads, 10),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
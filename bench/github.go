@@ -0,0 +1,93 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// WriteGitHubSummary appends a concise Markdown comparison table of the
+// results to the file named by the GITHUB_STEP_SUMMARY environment variable,
+// which GitHub Actions renders on the job page. It reports whether a summary
+// was written; outside of Actions (variable unset) it does nothing.
+func WriteGitHubSummary(results []Result) (bool, error) {
+	summaryPath := os.Getenv("GITHUB_STEP_SUMMARY")
+	if summaryPath == "" {
+		return false, nil
+	}
+	summaryFile, err := os.OpenFile(summaryPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return false, err
+	}
+	defer summaryFile.Close()
+
+	fmt.Fprintf(summaryFile, "### bucketbench results\n\n")
+	for _, result := range results {
+		if env := result.Environment; env != nil {
+			fmt.Fprintf(summaryFile, "%s, kernel %s, %d CPUs, cgroup %s\n\n",
+				env.Hostname, env.Kernel, env.CPUs, env.CgroupVersion)
+			break
+		}
+	}
+	fmt.Fprintf(summaryFile, "| Benchmark | Threads | Rate (ops/sec) | Run p95 (ms) | Errors |\n")
+	fmt.Fprintf(summaryFile, "| --- | ---: | ---: | ---: | ---: |\n")
+	for _, result := range results {
+		if result.Name == LimitBenchmarkName {
+			continue
+		}
+		var rate float64
+		if len(result.ThreadRates) > 0 {
+			rate = result.ThreadRates[len(result.ThreadRates)-1]
+		}
+		p95 := "-"
+		if value, ok := commandP95(result, "run"); ok {
+			p95 = fmt.Sprintf("%.2f", value)
+		}
+		errorCount := 0
+		for i := range result.Statistics {
+			for _, stats := range result.CommandStats(i) {
+				errorCount += stats.Errors
+			}
+		}
+		fmt.Fprintf(summaryFile, "| %s | %d | %.2f | %s | %d |\n",
+			result.Name, result.Threads, rate, p95, errorCount)
+	}
+	fmt.Fprintln(summaryFile)
+	return true, nil
+}
+
+// commandP95 computes the 95th-percentile duration (ms) of the named command
+// from the raw statistics of the result's final run; bounded-stats runs
+// which retained no raw iteration entries report no percentile
+func commandP95(result Result, cmd string) (float64, bool) {
+	if len(result.Statistics) == 0 {
+		return 0, false
+	}
+	var durations []float64
+	for _, stat := range result.Statistics[len(result.Statistics)-1] {
+		if stat.Daemon != nil {
+			continue
+		}
+		if duration, ok := stat.Durations[cmd]; ok {
+			durations = append(durations, float64(duration.Nanoseconds()/int64(time.Millisecond)))
+		}
+	}
+	if len(durations) == 0 {
+		return 0, false
+	}
+	return percentileOf(durations, 95), true
+}
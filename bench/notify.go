@@ -0,0 +1,124 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// notifyTimeout bounds the webhook POST so a dead endpoint can't hang the
+// end of an otherwise-finished run
+const notifyTimeout = 30 * time.Second
+
+// notification is the webhook payload; Text carries a human-readable summary
+// so Slack-style incoming webhooks render it directly, while the structured
+// fields serve generic receivers
+type notification struct {
+	Text     string `json:"text"`
+	Status   string `json:"status"`
+	Verdict  string `json:"verdict"`
+	Hostname string `json:"hostname"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+
+	Results []notificationResult `json:"results,omitempty"`
+}
+
+// notificationResult summarizes one benchmark result for the payload
+type notificationResult struct {
+	Name   string  `json:"name"`
+	Rate   float64 `json:"rate"`
+	Errors int     `json:"errors"`
+}
+
+// Notify POSTs a completion summary for the run to the given webhook URL;
+// the verdict is "pass" when the run finished without a run error or any
+// per-command errors, and "fail" otherwise
+func Notify(webhookURL string, results []Result, runErr error, elapsed time.Duration) error {
+	hostname, _ := os.Hostname()
+	payload := notification{
+		Status:   "completed",
+		Verdict:  "pass",
+		Hostname: hostname,
+		Duration: elapsed.Round(time.Second).String(),
+	}
+	if runErr != nil {
+		payload.Status = "failed"
+		payload.Verdict = "fail"
+		payload.Error = runErr.Error()
+	}
+
+	var lines []string
+	for _, result := range results {
+		if result.Name == LimitBenchmarkName {
+			continue
+		}
+		summary := notificationResult{Name: result.Name}
+		if len(result.ThreadRates) > 0 {
+			summary.Rate = result.ThreadRates[len(result.ThreadRates)-1]
+		}
+		for i := range result.Statistics {
+			for _, stats := range result.CommandStats(i) {
+				summary.Errors += stats.Errors
+			}
+		}
+		if summary.Errors > 0 {
+			payload.Verdict = "fail"
+		}
+		payload.Results = append(payload.Results, summary)
+		lines = append(lines, fmt.Sprintf("%s: %.2f ops/sec, %d errors", summary.Name, summary.Rate, summary.Errors))
+	}
+
+	header := fmt.Sprintf("bucketbench run %s on %s (%s, verdict: %s)",
+		payload.Status, hostname, payload.Duration, payload.Verdict)
+	if payload.Error != "" {
+		lines = append(lines, "error: "+payload.Error)
+	}
+	payload.Text = header
+	if len(lines) > 0 {
+		payload.Text = header + "\n" + strings.Join(lines, "\n")
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook POST to %s failed: %s: %s", webhookURL, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
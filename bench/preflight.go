@@ -0,0 +1,230 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/estesp/bucketbench/benches"
+	"github.com/estesp/bucketbench/driver"
+	"github.com/estesp/bucketbench/utils"
+)
+
+// PreflightCheck is the result of one readiness verification performed
+// before any benchmark work starts
+type PreflightCheck struct {
+	// Subject identifies what was checked (e.g. "daemon", "bundle")
+	Subject string
+	// Benchmark and Driver name the configuration entry the check belongs
+	// to; both are empty for host-wide checks
+	Benchmark string
+	Driver    string
+	OK        bool
+	Detail    string
+	// Hint suggests a remediation when the check failed
+	Hint string
+}
+
+// minPreflightDiskBytes is the free space below which the disk check
+// fails; image pulls and container writable layers routinely need more
+const minPreflightDiskBytes = 1 << 30
+
+// Preflight verifies the environment each benchmark needs — resolvable
+// binaries, reachable daemons, existing bundles, and free disk space —
+// so misconfiguration surfaces as one consolidated readiness report
+// instead of errors partway through a run
+func Preflight(ctx context.Context, benchmarks []benches.Benchmark) []PreflightCheck {
+	var checks []PreflightCheck
+
+	if free, err := utils.DiskFree(os.TempDir()); err == nil {
+		checks = append(checks, PreflightCheck{
+			Subject: "disk space",
+			OK:      free >= minPreflightDiskBytes,
+			Detail:  fmt.Sprintf("%.1f GB available under %s", float64(free)/(1<<30), os.TempDir()),
+			Hint:    "free up disk space; image pulls and writable layers need room",
+		})
+	}
+
+	for i := range benchmarks {
+		benchmark := &benchmarks[i]
+		for _, config := range benchmark.Drivers {
+			checks = append(checks, driverChecks(ctx, benchmark, config)...)
+		}
+	}
+	return checks
+}
+
+// PrintPreflight writes the consolidated readiness report and returns
+// the number of failed checks
+func PrintPreflight(out io.Writer, checks []PreflightCheck) int {
+	w := tabwriter.NewWriter(out, 4, 4, 2, ' ', 0)
+	fmt.Fprintf(out, "\nPRE-FLIGHT CHECKS\n\n")
+	failed := 0
+	for _, check := range checks {
+		status := " OK "
+		if !check.OK {
+			status = "FAIL"
+			failed++
+		}
+		scope := check.Subject
+		if check.Driver != "" {
+			scope = fmt.Sprintf("%s/%s %s", check.Benchmark, check.Driver, check.Subject)
+		}
+		fmt.Fprintf(w, "[%s]\t%s\t%s\t\n", status, scope, check.Detail)
+		if !check.OK && check.Hint != "" {
+			fmt.Fprintf(w, "\t  hint:\t%s\t\n", check.Hint)
+		}
+	}
+	w.Flush()
+	fmt.Fprintln(out, "")
+	return failed
+}
+
+// driverChecks runs the readiness checks for one benchmark driver entry
+func driverChecks(ctx context.Context, benchmark *benches.Benchmark, config benches.DriverConfig) []PreflightCheck {
+	var checks []PreflightCheck
+	check := func(subject string, ok bool, detail, hint string) {
+		checks = append(checks, PreflightCheck{
+			Subject:   subject,
+			Benchmark: benchmark.Name,
+			Driver:    config.Type,
+			OK:        ok,
+			Detail:    detail,
+			Hint:      hint,
+		})
+	}
+
+	driverType := driver.StringToType(config.Type)
+
+	// binary-backed drivers need their client binary resolvable up-front
+	if binary := driverBinary(driverType, config.ClientPath); binary != "" {
+		if resolved, err := utils.ResolveBinary(binary); err != nil {
+			check("binary", false, err.Error(),
+				fmt.Sprintf("install %s or set clientPath to its location", binary))
+		} else {
+			check("binary", true, resolved, "")
+		}
+	}
+
+	// the workload input: an image reference for daemon-backed drivers,
+	// an on-disk OCI bundle for the runtime binaries
+	switch driverType {
+	case driver.Runc, driver.CRun, driver.Youki:
+		rootFs := benchmark.RootFs
+		if config.RootFs != "" {
+			rootFs = config.RootFs
+		}
+		switch {
+		case rootFs == "":
+			check("bundle", false, "no rootfs configured",
+				"set a 'rootfs:' entry pointing at an OCI bundle directory")
+		default:
+			if _, err := os.Stat(rootFs); err != nil {
+				check("bundle", false, err.Error(),
+					"create the OCI bundle (config.json plus rootfs) at the configured path")
+			} else {
+				check("bundle", true, rootFs, "")
+			}
+		}
+	default:
+		image := benchmark.Image
+		if config.Image != "" {
+			image = config.Image
+		}
+		check("image", image != "", image,
+			"set an 'image:' entry so the driver has something to pull and run")
+	}
+
+	// daemon-backed drivers must be reachable before the run starts; the
+	// constructor plus an Info round-trip exercises the same connection
+	// path the benchmark will use
+	switch driverType {
+	case driver.Docker, driver.DockerCLI, driver.Containerd, driver.CRI:
+		drv, err := driver.New(ctx, &driver.Config{
+			DriverType:            driverType,
+			Path:                  config.ClientPath,
+			ContainerdNamespace:   config.ContainerdNamespace,
+			DockerHost:            config.DockerHost,
+			DockerTLSCertPath:     config.DockerTLSCertPath,
+			ContainerdTLSCertPath: config.ContainerdTLSCertPath,
+			LogDriver:             config.LogDriver,
+		})
+		if err != nil {
+			check("daemon", false, err.Error(), daemonHint(driverType))
+			break
+		}
+		info, err := drv.Info(ctx)
+		drv.Close()
+		if err != nil {
+			check("daemon", false, err.Error(), daemonHint(driverType))
+		} else {
+			check("daemon", true, firstLine(info), "")
+		}
+	}
+
+	return checks
+}
+
+// driverBinary returns the client binary a driver type shells out to, or
+// an empty string for purely API/socket-based drivers
+func driverBinary(driverType driver.Type, clientPath string) string {
+	var binary string
+	switch driverType {
+	case driver.DockerCLI:
+		binary = "docker"
+	case driver.Ctr:
+		binary = "ctr"
+	case driver.Runc:
+		binary = "runc"
+	case driver.CRun:
+		binary = "crun"
+	case driver.Youki:
+		binary = "youki"
+	default:
+		return ""
+	}
+	if clientPath != "" {
+		binary = clientPath
+	}
+	return binary
+}
+
+// daemonHint suggests how to get the daemon a driver type talks to into
+// a reachable state
+func daemonHint(driverType driver.Type) string {
+	switch driverType {
+	case driver.Docker, driver.DockerCLI:
+		return "start dockerd (or set dockerHost/DOCKER_HOST to a reachable daemon)"
+	case driver.Containerd:
+		return "start containerd (or point clientPath at its socket)"
+	case driver.CRI:
+		return "start a CRI runtime and set clientPath to its gRPC socket"
+	}
+	return ""
+}
+
+// firstLine trims a potentially multi-line Info string down to its first
+// line for the one-row-per-check report
+func firstLine(s string) string {
+	if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+		return s[:idx]
+	}
+	return s
+}
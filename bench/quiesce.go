@@ -0,0 +1,114 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// the system counts as quiet when the 1-minute load average per CPU and
+	// the dirty page volume are both below these thresholds
+	quiesceLoadPerCPU  = 0.5
+	quiesceDirtyBytes  = 64 * 1024 * 1024
+	quiescePollPeriod  = 2 * time.Second
+	quiesceWaitTimeout = 2 * time.Minute
+)
+
+// Quiesce waits until the host looks quiet — low load average and little
+// dirty page writeback pending — so successive driver runs start from
+// comparable system states. A host that never settles within the timeout
+// is logged and the run proceeds; only context cancellation aborts the
+// wait. Hosts without /proc (non-Linux) skip the checks entirely.
+func Quiesce(ctx context.Context) error {
+	deadline := time.Now().Add(quiesceWaitTimeout)
+	for {
+		load, loadErr := loadAverage()
+		dirty, dirtyErr := dirtyPageBytes()
+		if loadErr != nil && dirtyErr != nil {
+			log.Debug("quiesce: load and dirty page data unavailable on this host; skipping")
+			return nil
+		}
+		loadLimit := quiesceLoadPerCPU * float64(runtime.NumCPU())
+		if (loadErr != nil || load <= loadLimit) && (dirtyErr != nil || dirty <= quiesceDirtyBytes) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			log.Warnf("quiesce: host still busy after %v (load %.2f, dirty %d MB); proceeding anyway",
+				quiesceWaitTimeout, load, dirty/(1024*1024))
+			return nil
+		}
+		log.Infof("quiesce: waiting for host to settle (load %.2f > %.2f or dirty %d MB > %d MB)",
+			load, loadLimit, dirty/(1024*1024), quiesceDirtyBytes/(1024*1024))
+		select {
+		case <-time.After(quiescePollPeriod):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// DropCaches syncs outstanding writes and asks the kernel to drop the page
+// cache, dentries, and inodes, so each driver starts with a cold cache
+// instead of inheriting the previous driver's. Requires root and Linux.
+func DropCaches() error {
+	syscall.Sync()
+	return os.WriteFile("/proc/sys/vm/drop_caches", []byte("3\n"), 0200)
+}
+
+// loadAverage returns the 1-minute load average from /proc/loadavg
+func loadAverage() (float64, error) {
+	data, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, os.ErrInvalid
+	}
+	return strconv.ParseFloat(fields[0], 64)
+}
+
+// dirtyPageBytes returns the volume of dirty pages awaiting writeback from
+// the "Dirty:" line of /proc/meminfo
+func dirtyPageBytes() (uint64, error) {
+	data, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "Dirty:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			break
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return kb * 1024, nil
+	}
+	return 0, os.ErrInvalid
+}
@@ -0,0 +1,205 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ociRefNameAnnotation is the standard annotation naming the reference an
+// OCI image layout manifest was exported under
+const ociRefNameAnnotation = "org.opencontainers.image.ref.name"
+
+// ociDescriptor is the subset of an OCI content descriptor the registry
+// needs from a layout's index.json
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// ociIndex is the subset of an OCI layout index.json listing its manifests
+type ociIndex struct {
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+// LocalRegistry serves a read-only OCI distribution endpoint backed by an
+// image layout directory (e.g. from 'ctr images export' or 'skopeo copy'),
+// so pull benchmarks hit a local, reproducible registry instead of Docker
+// Hub with its rate limits and WAN variance
+type LocalRegistry struct {
+	layoutDir string
+	addr      string
+	server    *http.Server
+	// tags maps the reference names found in the layout index (full refs,
+	// host-stripped name:tag forms, and bare tags) to manifest digests
+	tags map[string]string
+}
+
+// StartLocalRegistry serves the OCI image layout at layoutDir on
+// 127.0.0.1:port (an ephemeral port when zero) and returns the running
+// registry; the caller closes it when the run finishes
+func StartLocalRegistry(layoutDir string, port int) (*LocalRegistry, error) {
+	data, err := os.ReadFile(filepath.Join(layoutDir, "index.json"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read OCI layout index in %q", layoutDir)
+	}
+	var index ociIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, errors.Wrapf(err, "can't parse OCI layout index in %q", layoutDir)
+	}
+
+	tags := make(map[string]string)
+	for _, desc := range index.Manifests {
+		ref := desc.Annotations[ociRefNameAnnotation]
+		if ref == "" {
+			continue
+		}
+		// index the full ref, the host-stripped name:tag, and the bare tag
+		// so clients can resolve whichever form they request
+		tags[ref] = desc.Digest
+		tags[stripRegistryHost(ref)] = desc.Digest
+		if idx := strings.LastIndex(ref, ":"); idx >= 0 {
+			tags[ref[idx+1:]] = desc.Digest
+		}
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("OCI layout in %q has no tagged manifests", layoutDir)
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't listen for local registry")
+	}
+	registry := &LocalRegistry{
+		layoutDir: layoutDir,
+		addr:      listener.Addr().String(),
+		tags:      tags,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/", registry.handle)
+	registry.server = &http.Server{Handler: mux}
+	go registry.server.Serve(listener)
+	return registry, nil
+}
+
+// Addr returns the host:port the registry is serving on
+func (l *LocalRegistry) Addr() string {
+	return l.addr
+}
+
+// Close shuts the registry down
+func (l *LocalRegistry) Close() error {
+	return l.server.Close()
+}
+
+// RewriteImageRef points an image reference at the local registry,
+// replacing any registry host already present; an empty reference passes
+// through unchanged
+func (l *LocalRegistry) RewriteImageRef(image string) string {
+	if image == "" {
+		return image
+	}
+	return l.addr + "/" + stripRegistryHost(image)
+}
+
+// stripRegistryHost removes a leading registry host component (identified
+// the way container engines do: it contains a dot, a port, or is
+// "localhost") from an image reference
+func stripRegistryHost(image string) string {
+	host, rest, ok := strings.Cut(image, "/")
+	if !ok {
+		return image
+	}
+	if strings.ContainsAny(host, ".:") || host == "localhost" {
+		return rest
+	}
+	return image
+}
+
+// handle serves the read-only subset of the distribution API pulls use:
+// the version check, manifests by tag or digest, and blobs by digest
+func (l *LocalRegistry) handle(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	if path == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	switch {
+	case strings.Contains(path, "/manifests/"):
+		idx := strings.LastIndex(path, "/manifests/")
+		name, ref := path[:idx], path[idx+len("/manifests/"):]
+		digest := ref
+		if !strings.HasPrefix(ref, "sha256:") {
+			var ok bool
+			if digest, ok = l.tags[name+":"+ref]; !ok {
+				if digest, ok = l.tags[ref]; !ok {
+					http.NotFound(w, req)
+					return
+				}
+			}
+		}
+		l.serveBlob(w, req, digest, true)
+	case strings.Contains(path, "/blobs/"):
+		idx := strings.LastIndex(path, "/blobs/")
+		l.serveBlob(w, req, path[idx+len("/blobs/"):], false)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// serveBlob writes a blob from the layout's content store; manifests are
+// read first so the response carries their declared media type
+func (l *LocalRegistry) serveBlob(w http.ResponseWriter, req *http.Request, digest string, manifest bool) {
+	hex := strings.TrimPrefix(digest, "sha256:")
+	if !strings.HasPrefix(digest, "sha256:") || strings.Contains(hex, "/") {
+		http.NotFound(w, req)
+		return
+	}
+	file := filepath.Join(l.layoutDir, "blobs", "sha256", hex)
+	if manifest {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			http.NotFound(w, req)
+			return
+		}
+		var media struct {
+			MediaType string `json:"mediaType"`
+		}
+		mediaType := "application/vnd.oci.image.manifest.v1+json"
+		if err := json.Unmarshal(data, &media); err == nil && media.MediaType != "" {
+			mediaType = media.MediaType
+		}
+		w.Header().Set("Content-Type", mediaType)
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+		if req.Method == http.MethodHead {
+			return
+		}
+		w.Write(data)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Docker-Content-Digest", digest)
+	http.ServeFile(w, req, file)
+}
@@ -0,0 +1,72 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"fmt"
+
+	"github.com/estesp/bucketbench/benches"
+	"github.com/montanaflynn/stats"
+	log "github.com/sirupsen/logrus"
+)
+
+// CombineRepetitions folds the results of executing the same benchmark
+// and driver entry several times into one: raw statistics are concatenated
+// so the combined per-command statistics cover every repetition,
+// bounded-memory summaries are merged, and the reported rate is the mean
+// across repetitions. The per-repetition rate spread is logged so outlier
+// repetitions are visible alongside the combined numbers.
+func CombineRepetitions(results []Result) Result {
+	if len(results) == 1 {
+		return results[0]
+	}
+
+	var (
+		rates    []float64
+		allStats []benches.RunStatistics
+		summary  *benches.RunSummary
+	)
+	for _, result := range results {
+		if len(result.ThreadRates) > 0 {
+			rates = append(rates, result.ThreadRates[len(result.ThreadRates)-1])
+		}
+		if len(result.Statistics) > 0 {
+			allStats = append(allStats, result.Statistics[0]...)
+		}
+		if len(result.Summaries) > 0 && result.Summaries[0] != nil {
+			summary = benches.MergeSummaries(summary, result.Summaries[0])
+		}
+	}
+
+	combined := results[0]
+	combined.DriverInfo = fmt.Sprintf("%s [%d repetitions]", results[0].DriverInfo, len(results))
+	combined.Statistics = [][]benches.RunStatistics{allStats}
+	combined.Summaries = []*benches.RunSummary{summary}
+
+	if len(rates) > 0 {
+		mean, _ := stats.Mean(rates)
+		min, _ := stats.Min(rates)
+		max, _ := stats.Max(rates)
+		stddev, _ := stats.StandardDeviation(rates)
+		var cv float64
+		if mean > 0 {
+			cv = 100 * stddev / mean
+		}
+		combined.ThreadRates = []float64{mean}
+		log.Infof("%s rate across %d repetitions: mean %.2f, min %.2f, max %.2f, CV %.1f%%",
+			results[0].Name, len(results), mean, min, max, cv)
+	}
+	return combined
+}
@@ -0,0 +1,261 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+func getDelta(before, after float64) float64 {
+	switch {
+	case before != 0:
+		return after / before
+	case after == 0:
+		return 1
+	default:
+		return math.Inf(1)
+	}
+}
+
+// PrintReport writes the summary rate, detailed command, and (optionally)
+// overhead tables for a set of benchmark results to the given writer
+func PrintReport(out io.Writer, maxThreads int, results []Result, overhead bool, legacyMode bool) {
+	w := tabwriter.NewWriter(out, 10, 4, 2, ' ', tabwriter.AlignRight)
+
+	// the environment is captured per result but identical for all local
+	// runs, so report the first one found
+	for _, result := range results {
+		if env := result.Environment; env != nil {
+			fmt.Fprintf(out, "\nENVIRONMENT: %s (%s/%s), kernel %s\n", env.Hostname, env.OS, env.Arch, env.Kernel)
+			fmt.Fprintf(out, "%s, %d CPUs, %.1f GB RAM, cgroup %s\n",
+				env.CPUModel, env.CPUs, float64(env.MemTotalBytes)/(1024*1024*1024), env.CgroupVersion)
+			break
+		}
+	}
+
+	fmt.Fprintf(out, "\nSUMMARY TIMINGS/THREAD RATES\n\n")
+	fmt.Fprintf(w, " \tIter/Thd\t1 thrd")
+	for i := 2; i <= maxThreads; i++ {
+		fmt.Fprintf(w, "\t%d thrds", i)
+	}
+	fmt.Fprintln(w, "\t ")
+
+	for _, result := range results {
+		if legacyMode {
+			outputThreadRatesLegacy(w, result)
+		} else {
+			outputThreadRates(w, result)
+		}
+	}
+	w.Flush()
+	fmt.Fprintln(out, "")
+
+	// headline success rates so failures are visible without reading the
+	// per-command error columns in the detailed tables
+	fmt.Fprintf(out, "SUCCESS RATES\n\n")
+	fmt.Fprintf(w, " \tOps\tErrors\tSuccess\t\n")
+	for _, result := range results {
+		if result.Name == LimitBenchmarkName {
+			continue
+		}
+		runs := 1
+		if legacyMode {
+			runs = result.Threads
+		}
+		for i := 0; i < runs; i++ {
+			var ops, errors int
+			for _, stats := range result.CommandStats(i) {
+				ops += stats.Count + stats.Errors
+				errors += stats.Errors
+			}
+			success := 100.0
+			if ops > 0 {
+				success = 100 * float64(ops-errors) / float64(ops)
+			}
+			threads := result.Threads
+			if legacyMode {
+				threads = i + 1
+			}
+			fmt.Fprintf(w, "%s:%d\t%d\t%d\t%6.2f %%\t\n", result.Name, threads, ops, errors, success)
+		}
+	}
+	w.Flush()
+	fmt.Fprintln(out, "")
+
+	cmdList := []string{"create", "pull", "unpack", "sandbox", "run", "firstProcess", "createContainer", "startContainer", "networkSetup", "healthy", "taskStartEvent", "createEvent", "startEvent", "createVolume", "snapshot", "commit", "diff", "exec", "wait", "logs", "checkpoint", "restore", "pause", "resume", "restartDaemon", "reconcile", "chaosRecovery", "stop", "taskExitEvent", "dieEvent", "stopSandbox", "delete", "removeSandbox", "removeVolume"}
+	fmt.Fprintf(out, "DETAILED COMMAND TIMINGS/STATISTICS\n")
+	// output per-command timings across the runs as well
+	for _, result := range results {
+		// only 1 result
+		if result.Name == LimitBenchmarkName {
+			// the limit "benchmark" has no detailed statistics
+			continue
+		}
+		if legacyMode {
+			outputDetailCommandStatsLegacy(result, w, cmdList)
+		} else {
+			outputDetailCommandStats(result, w, cmdList)
+		}
+
+		w.Flush()
+		fmt.Fprintln(out, "")
+	}
+
+	w.Flush()
+
+	if overhead {
+		fmt.Fprintf(w, "\n")
+		fmt.Fprintf(w, "OVERHEAD\n")
+
+		var overheadResults []Result
+		for _, res := range results {
+			if res.Name == LimitBenchmarkName {
+				continue
+			}
+			overheadResults = append(overheadResults, res)
+		}
+
+		if len(overheadResults) == 0 {
+			fmt.Fprint(w, "No data")
+			w.Flush()
+			return
+		}
+
+		// Preprocess statistics before output
+		metrics := make([][]MetricsSummary, len(overheadResults))
+		for i, res := range overheadResults {
+			metrics[i] = make([]MetricsSummary, res.Threads)
+			for j := 0; j < len(res.Statistics); j++ {
+				metrics[i][j] = res.Metrics(j)
+			}
+		}
+
+		for i, res := range overheadResults {
+
+			fmt.Fprintf(w, "\n%s\n\n", res.DriverInfo)
+
+			fmt.Fprintf(w, "Bench / driver / threads\tMin\tMax\tAvg\tP95\tP99\tSust\tMin\tMax\tAvg\tP95\tP99\tSust\tRead\tWrite\tRd/s\tWr/s\tRx\tTx\tFDs\tThr\tMem %%\tCPU x\t\n")
+
+			for j := 0; j < res.Threads; j++ {
+				m := metrics[i][j]
+
+				fmt.Fprintf(w,
+					"%s:%d\t%d MB\t%d MB\t%d MB\t%d MB\t%d MB\t%d MB\t%.2f %%\t%.2f %%\t%.2f %%\t%.2f %%\t%.2f %%\t%.2f %%\t%.1f MB\t%.1f MB\t%.1f\t%.1f\t%.1f MB\t%.1f MB\t%d\t%d\t",
+					res.Name, j+1,
+					m.MinMem, m.MaxMem, m.AvgMem, m.P95Mem, m.P99Mem, m.SustMem,
+					m.MinCPU, m.MaxCPU, m.AvgCPU, m.P95CPU, m.P99CPU, m.SustCPU,
+					m.ReadMB, m.WriteMB, m.ReadIOPS, m.WriteIOPS,
+					m.NetRxMB, m.NetTxMB, m.MaxFDs, m.MaxThreads)
+
+				if i > 0 {
+					// Output overhead comparing to first result
+
+					if j < overheadResults[0].Threads {
+						// Mem percent change, ranging from -100% up.
+						mem := 100*getDelta(float64(metrics[0][j].AvgMem), float64(m.AvgMem)) - 100
+						cpu := getDelta(metrics[0][j].AvgCPU, m.AvgCPU)
+
+						fmt.Fprintf(w, "%+.2f%%\t%.2fx\t", mem, cpu)
+					}
+				}
+
+				fmt.Fprint(w, "\n")
+			}
+		}
+
+		w.Flush()
+	}
+}
+
+func outputDetailCommandStatsLegacy(result Result, w *tabwriter.Writer, cmdList []string) {
+	for i := 0; i < result.Threads; i++ {
+		fmt.Fprintf(w, "%s:%d\tMin\tMax\tAvg\tMedian\tStddev\tCI95\tCV\tErrors\tRetries\t\n", result.Name, i+1)
+		cmdTimings := result.CommandStats(i)
+		// given we are working with a map, but we want consistent ordering in the output
+		// we walk a slice of commands in a natural/expected order and output stats for
+		// those that were used during the specific run
+		for _, cmd := range cmdList {
+			if stats, ok := cmdTimings[cmd]; ok {
+				outputCommandStatRow(w, cmd, stats)
+			}
+		}
+	}
+}
+
+func outputDetailCommandStats(result Result, w *tabwriter.Writer, cmdList []string) {
+	fmt.Fprintf(w, "%s:%d\tMin\tMax\tAvg\tMedian\tStddev\tCI95\tCV\tErrors\tRetries\t\n", result.Name, result.Threads)
+	cmdTimings := result.CommandStats(0)
+	for _, cmd := range cmdList {
+		if stats, ok := cmdTimings[cmd]; ok {
+			outputCommandStatRow(w, cmd, stats)
+		}
+	}
+}
+
+// highVarianceCV is the coefficient-of-variation percentage above which a
+// command's mean is flagged as unreliable for the configured iteration count
+const highVarianceCV = 50.0
+
+func outputCommandStatRow(w *tabwriter.Writer, cmd string, stats CommandStats) {
+	fmt.Fprintf(w, "%s\t%6.2f\t%6.2f\t%6.2f\t%6.2f\t%6.2f\t±%.2f\t%.0f %%\t%d\t%d\t\n",
+		cmd, stats.Min, stats.Max, stats.Avg, stats.Median, stats.Stddev, stats.CI95, stats.CV, stats.Errors, stats.Retries)
+	if stats.CV > highVarianceCV && stats.Count > 1 {
+		fmt.Fprintf(w, "  %s: high variance (CV %.0f%%); consider more iterations\t\t\t\t\t\t\t\t\t\t\n", cmd, stats.CV)
+	}
+	if len(stats.ErrorClasses) > 0 {
+		fmt.Fprintf(w, "  %s errors: %s\t\t\t\t\t\t\t\t\t\t\n", cmd, formatErrorClasses(stats.ErrorClasses))
+	}
+}
+
+func outputThreadRates(w *tabwriter.Writer, result Result) {
+	if result.Name == LimitBenchmarkName {
+		outputThreadRatesLegacy(w, result)
+		return
+	}
+
+	fmt.Fprintf(w, "%s\t%d", result.Name, result.Iterations)
+	for i := 1; i <= result.Threads; i++ {
+		fmt.Fprintf(w, "\t")
+	}
+	fmt.Fprintf(w, "%7.2f\t ", result.ThreadRates[0])
+}
+
+func outputThreadRatesLegacy(w *tabwriter.Writer, result Result) {
+	fmt.Fprintf(w, "%s\t%d\t%7.2f", result.Name, result.Iterations, result.ThreadRates[0])
+	for i := 1; i < result.Threads; i++ {
+		fmt.Fprintf(w, "\t%7.2f", result.ThreadRates[i])
+	}
+	fmt.Fprintln(w, "\t ")
+}
+
+// formatErrorClasses renders a per-command error breakdown (e.g.
+// "timeout=2 not-found=1") in a stable order for the detailed output
+func formatErrorClasses(classes map[string]int) string {
+	keys := make([]string, 0, len(classes))
+	for class := range classes {
+		keys = append(keys, class)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, class := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%d", class, classes[class]))
+	}
+	return strings.Join(parts, " ")
+}
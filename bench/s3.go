@@ -0,0 +1,203 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// S3Sink uploads result artifact files to an S3-compatible object store so
+// scheduled runs on ephemeral machines don't lose their data. Requests are
+// signed with AWS Signature Version 4 directly rather than pulling in an SDK
+// dependency; any store speaking the S3 PUT Object API (AWS, MinIO, Ceph RGW)
+// works via the Endpoint field.
+type S3Sink struct {
+	// Endpoint is the base URL of the object store, e.g.
+	// "https://s3.amazonaws.com" or "http://minio.internal:9000"
+	Endpoint string
+	Region   string
+	Bucket   string
+	// KeyPrefix is prepended to each uploaded file name; the placeholders
+	// {hostname}, {date}, {time}, and {unix} are expanded when the sink is
+	// used so scheduled jobs get distinct, sortable key names
+	KeyPrefix string
+
+	accessKey string
+	secretKey string
+}
+
+// NewS3SinkFromEnv builds an upload sink for the given bucket, reading
+// credentials from the conventional AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY
+// environment variables and the region from AWS_REGION (default us-east-1)
+func NewS3SinkFromEnv(endpoint, bucket, keyPrefix string) (*S3Sink, error) {
+	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("S3 upload requires AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY in the environment")
+	}
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &S3Sink{
+		Endpoint:  strings.TrimSuffix(endpoint, "/"),
+		Region:    region,
+		Bucket:    bucket,
+		KeyPrefix: keyPrefix,
+		accessKey: accessKey,
+		secretKey: secretKey,
+	}, nil
+}
+
+// UploadAll uploads each named artifact file under the expanded key prefix;
+// empty names are skipped so callers can pass their full flag set directly
+func (s *S3Sink) UploadAll(ctx context.Context, filenames ...string) error {
+	prefix := s.expandPrefix(time.Now().UTC())
+	for _, filename := range filenames {
+		if filename == "" {
+			continue
+		}
+		key := prefix + filepath.Base(filename)
+		if err := s.upload(ctx, filename, key); err != nil {
+			return err
+		}
+		log.Infof("uploaded %s to s3://%s/%s", filename, s.Bucket, key)
+	}
+	return nil
+}
+
+// expandPrefix substitutes the supported placeholders in the key prefix and
+// ensures it ends with a path separator
+func (s *S3Sink) expandPrefix(now time.Time) string {
+	hostname, _ := os.Hostname()
+	replacer := strings.NewReplacer(
+		"{hostname}", hostname,
+		"{date}", now.Format("2006-01-02"),
+		"{time}", now.Format("150405"),
+		"{unix}", fmt.Sprintf("%d", now.Unix()),
+	)
+	prefix := replacer.Replace(s.KeyPrefix)
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+// upload PUTs one file to the bucket under the given key using path-style
+// addressing, which both AWS and self-hosted stores accept
+func (s *S3Sink) upload(ctx context.Context, filename, key string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return errors.Wrapf(err, "can't read artifact file %q", filename)
+	}
+
+	objectURL := fmt.Sprintf("%s/%s/%s", s.Endpoint, s.Bucket, key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	s.sign(req, data)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "upload of %q failed", filename)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload of %q failed: %s: %s", filename, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// sign adds the AWS Signature Version 4 authorization headers for a request
+// with the given payload
+func (s *S3Sink) sign(req *http.Request, payload []byte) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	payloadHash := hexSHA256(payload)
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL),
+		"", // no query string on PUT Object
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{dateStamp, s.Region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.secretKey), dateStamp)
+	signingKey = hmacSHA256(signingKey, s.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalURI returns the URI-encoded path as SigV4 expects it, with each
+// segment escaped but the separators preserved
+func canonicalURI(u *url.URL) string {
+	segments := strings.Split(u.EscapedPath(), "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(segment, "+", "%20")
+	}
+	return strings.Join(segments, "/")
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
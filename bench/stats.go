@@ -0,0 +1,365 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/estesp/bucketbench/benches"
+	"github.com/montanaflynn/stats"
+	log "github.com/sirupsen/logrus"
+)
+
+// CommandStats summarizes one command's timing and error results over a
+// run; durations are in milliseconds
+type CommandStats struct {
+	// Count is the number of successfully timed operations for the command
+	Count  int
+	Min    float64
+	Max    float64
+	Avg    float64
+	Median float64
+	Stddev float64
+	// CI95 is the half-width of the 95% confidence interval of the mean,
+	// and CV the coefficient of variation as a percentage of the mean;
+	// together they show whether the iteration count was large enough for
+	// the mean to be meaningful
+	CI95    float64
+	CV      float64
+	Errors  int
+	Retries int
+	// ErrorClasses breaks the error count down by classified failure kind
+	// (timeout, not-found, etc.) when any errors were classified
+	ErrorClasses map[string]int
+}
+
+// MetricsSummary summarizes the daemon overhead samples of one run
+type MetricsSummary struct {
+	MinMem uint64
+	MaxMem uint64
+	AvgMem uint64
+	MinCPU float64
+	MaxCPU float64
+	AvgCPU float64
+	// tail percentiles and sustained peaks (highest mean over a short
+	// contiguous sample window); transient spikes matter for capacity
+	// planning and the min/max/avg trio hides them
+	P95Mem  uint64
+	P99Mem  uint64
+	SustMem uint64
+	P95CPU  float64
+	P99CPU  float64
+	SustCPU float64
+	// block and network I/O deltas over the sampled window, with average
+	// block op rates
+	ReadMB    float64
+	WriteMB   float64
+	ReadIOPS  float64
+	WriteIOPS float64
+	NetRxMB   float64
+	NetTxMB   float64
+	// peak fd and thread counts observed across the sampled window
+	MaxFDs     uint64
+	MaxThreads uint64
+}
+
+// CommandStats returns the per-command statistics for one run of the
+// result, converting the online aggregation when the benchmark streamed
+// its statistics and parsing the raw entries otherwise
+func (r Result) CommandStats(index int) map[string]CommandStats {
+	if index < len(r.Summaries) && r.Summaries[index] != nil {
+		return summaryStats(r.Summaries[index])
+	}
+	return parseStats(r.Statistics[index])
+}
+
+// Metrics returns the daemon overhead summary for one run of the result
+func (r Result) Metrics(index int) MetricsSummary {
+	return parseMetrics(r.Statistics[index])
+}
+
+// summaryStats adapts a bounded-memory run summary to the per-command
+// statistics form
+func summaryStats(summary *benches.RunSummary) map[string]CommandStats {
+	result := make(map[string]CommandStats, len(summary.Commands))
+	for key, cmd := range summary.Commands {
+		ci95, cv := ciAndCV(cmd.Avg, cmd.Stddev, cmd.Count)
+		result[key] = CommandStats{
+			Count:        cmd.Count,
+			Min:          cmd.Min,
+			Max:          cmd.Max,
+			Avg:          cmd.Avg,
+			Median:       cmd.Median,
+			Stddev:       cmd.Stddev,
+			CI95:         ci95,
+			CV:           cv,
+			Errors:       cmd.Errors,
+			Retries:      cmd.Retries,
+			ErrorClasses: cmd.ErrorClasses,
+		}
+	}
+	return result
+}
+
+func filterStats(stats []benches.RunStatistics, check func(benches.RunStatistics) bool) (ret []benches.RunStatistics) {
+	for _, stat := range stats {
+		if check(stat) {
+			ret = append(ret, stat)
+		}
+	}
+
+	return
+}
+
+func parseStats(statistics []benches.RunStatistics) map[string]CommandStats {
+	result := make(map[string]CommandStats)
+	durationSeq := make(map[string][]float64)
+	errorSeq := make(map[string][]int)
+	retrySeq := make(map[string][]int)
+
+	statistics = filterStats(statistics, func(stat benches.RunStatistics) bool {
+		return stat.Daemon == nil
+	})
+
+	iterations := len(statistics)
+
+	durationKeys := make([]string, len(statistics[0].Durations))
+	i := 0
+	for k := range statistics[0].Durations {
+		durationKeys[i] = k
+		i++
+	}
+	for i := 0; i < iterations; i++ {
+		for key, duration := range statistics[i].Durations {
+			durationSeq[key] = append(durationSeq[key], float64(duration.Nanoseconds()/int64(time.Millisecond)))
+		}
+		for key, errors := range statistics[i].Errors {
+			errorSeq[key] = append(errorSeq[key], errors)
+		}
+		for key, retries := range statistics[i].Retries {
+			retrySeq[key] = append(retrySeq[key], retries)
+		}
+	}
+	for _, key := range durationKeys {
+		// take the durations for this key and perform
+		// several math/statistical functions:
+		min, err := stats.Min(durationSeq[key])
+		if err != nil {
+			log.Errorf("Error finding stats.Min(): %v", err)
+		}
+		max, err := stats.Max(durationSeq[key])
+		if err != nil {
+			log.Errorf("Error finding stats.Max(): %v", err)
+		}
+		average, err := stats.Mean(durationSeq[key])
+		if err != nil {
+			log.Errorf("Error finding stats.Average(): %v", err)
+		}
+		median, err := stats.Median(durationSeq[key])
+		if err != nil {
+			log.Errorf("Error finding stats.Median(): %v", err)
+		}
+		stddev, err := stats.StandardDeviation(durationSeq[key])
+		if err != nil {
+			log.Errorf("Error finding stats.StdDev(): %v", err)
+		}
+		var errors int
+		if errorSlice, ok := errorSeq[key]; ok {
+			errors = intSum(errorSlice)
+		}
+		var retries int
+		if retrySlice, ok := retrySeq[key]; ok {
+			retries = intSum(retrySlice)
+		}
+		// classified failures are recorded under "<command>:<class>" keys
+		var errorClasses map[string]int
+		for ekey, slice := range errorSeq {
+			if strings.HasPrefix(ekey, key+":") {
+				if errorClasses == nil {
+					errorClasses = make(map[string]int)
+				}
+				errorClasses[strings.TrimPrefix(ekey, key+":")] = intSum(slice)
+			}
+		}
+		ci95, cv := ciAndCV(average, stddev, len(durationSeq[key]))
+		result[key] = CommandStats{
+			Count:        len(durationSeq[key]),
+			Min:          min,
+			Max:          max,
+			Avg:          average,
+			Median:       median,
+			Stddev:       stddev,
+			CI95:         ci95,
+			CV:           cv,
+			Errors:       errors,
+			Retries:      retries,
+			ErrorClasses: errorClasses,
+		}
+	}
+	return result
+}
+
+func parseMetrics(metrics []benches.RunStatistics) MetricsSummary {
+	var mems []float64
+	var cpus []float64
+
+	metrics = filterStats(metrics, func(stat benches.RunStatistics) bool {
+		return stat.Daemon != nil
+	})
+
+	var maxFDs, maxThreads uint64
+	for _, m := range metrics {
+		mems = append(mems, float64(m.Daemon.Mem))
+		cpus = append(cpus, m.Daemon.CPU)
+		if m.Daemon.FDs > maxFDs {
+			maxFDs = m.Daemon.FDs
+		}
+		if m.Daemon.Threads > maxThreads {
+			maxThreads = m.Daemon.Threads
+		}
+	}
+
+	minMem, err := stats.Min(mems)
+	if err != nil {
+		log.Errorf("error finding min mem: %v", err)
+	}
+
+	maxMem, err := stats.Max(mems)
+	if err != nil {
+		log.Errorf("error finding max mem: %v", err)
+	}
+
+	avgMem, err := stats.Mean(mems)
+	if err != nil {
+		log.Errorf("error finding avg mem: %v", err)
+	}
+
+	minCPU, err := stats.Min(cpus)
+	if err != nil {
+		log.Errorf("error finding min cpu: %v", err)
+	}
+
+	maxCPU, err := stats.Max(cpus)
+	if err != nil {
+		log.Errorf("error finding max cpu: %v", err)
+	}
+
+	avgCPU, err := stats.Mean(cpus)
+	if err != nil {
+		log.Errorf("error finding avg cpu: %v", err)
+	}
+
+	// block I/O counters are cumulative, so report the delta between the
+	// first and last samples with op rates averaged over that window
+	var readMB, writeMB, readIOPS, writeIOPS, netRxMB, netTxMB float64
+	if len(metrics) > 1 {
+		first, last := metrics[0].Daemon, metrics[len(metrics)-1].Daemon
+		window := metrics[len(metrics)-1].Timestamp.Sub(metrics[0].Timestamp).Seconds()
+		readMB = float64(last.BlkReadBytes-first.BlkReadBytes) / (1024 * 1024)
+		writeMB = float64(last.BlkWriteBytes-first.BlkWriteBytes) / (1024 * 1024)
+		netRxMB = float64(last.NetRxBytes-first.NetRxBytes) / (1024 * 1024)
+		netTxMB = float64(last.NetTxBytes-first.NetTxBytes) / (1024 * 1024)
+		if window > 0 {
+			readIOPS = float64(last.BlkReadOps-first.BlkReadOps) / window
+			writeIOPS = float64(last.BlkWriteOps-first.BlkWriteOps) / window
+		}
+	}
+
+	return MetricsSummary{
+		MinMem:     uint64(minMem),
+		MaxMem:     uint64(maxMem),
+		AvgMem:     uint64(avgMem),
+		MinCPU:     minCPU,
+		MaxCPU:     maxCPU,
+		AvgCPU:     avgCPU,
+		P95Mem:     uint64(percentileOf(mems, 95)),
+		P99Mem:     uint64(percentileOf(mems, 99)),
+		SustMem:    uint64(sustainedPeak(mems)),
+		P95CPU:     percentileOf(cpus, 95),
+		P99CPU:     percentileOf(cpus, 99),
+		SustCPU:    sustainedPeak(cpus),
+		ReadMB:     readMB,
+		WriteMB:    writeMB,
+		ReadIOPS:   readIOPS,
+		WriteIOPS:  writeIOPS,
+		NetRxMB:    netRxMB,
+		NetTxMB:    netTxMB,
+		MaxFDs:     maxFDs,
+		MaxThreads: maxThreads,
+	}
+}
+
+// percentileOf returns the given percentile of the sequence, logging and
+// returning zero when it cannot be computed
+func percentileOf(seq []float64, percent float64) float64 {
+	value, err := stats.Percentile(seq, percent)
+	if err != nil {
+		log.Errorf("error finding p%.0f: %v", percent, err)
+	}
+	return value
+}
+
+// sustainedPeakWindow is the number of consecutive samples a spike must span
+// to count as sustained in the peak-hold metric
+const sustainedPeakWindow = 5
+
+// sustainedPeak returns the highest mean over any contiguous window of
+// sustainedPeakWindow samples, so a spike only registers when it persists
+// across the window; shorter sequences fall back to the overall mean
+func sustainedPeak(seq []float64) float64 {
+	if len(seq) == 0 {
+		return 0
+	}
+	if len(seq) < sustainedPeakWindow {
+		mean, _ := stats.Mean(seq)
+		return mean
+	}
+	var windowSum float64
+	for _, v := range seq[:sustainedPeakWindow] {
+		windowSum += v
+	}
+	peak := windowSum
+	for i := sustainedPeakWindow; i < len(seq); i++ {
+		windowSum += seq[i] - seq[i-sustainedPeakWindow]
+		if windowSum > peak {
+			peak = windowSum
+		}
+	}
+	return peak / sustainedPeakWindow
+}
+
+// ciAndCV derives the 95% confidence half-width of the mean and the
+// coefficient of variation (as a percentage) from a command's summary
+// statistics; both are zero when there are too few samples to compute them
+func ciAndCV(avg, stddev float64, count int) (float64, float64) {
+	var ci95, cv float64
+	if count > 1 {
+		ci95 = 1.96 * stddev / math.Sqrt(float64(count))
+	}
+	if avg > 0 {
+		cv = 100 * stddev / avg
+	}
+	return ci95, cv
+}
+
+func intSum(slice []int) int {
+	var total int
+	for _, val := range slice {
+		total += val
+	}
+	return total
+}
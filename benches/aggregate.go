@@ -0,0 +1,215 @@
+package benches
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// reservoirSize bounds how many duration samples per command are retained
+// for quantile estimation when bounded-memory aggregation is enabled
+const reservoirSize = 1024
+
+// onlineStats summarizes one command's duration sequence without retaining
+// it: count/min/max/mean/variance are tracked exactly with Welford's
+// algorithm, and a uniform reservoir sample stands in for the full sequence
+// when estimating the median
+type onlineStats struct {
+	count     int
+	min       float64
+	max       float64
+	mean      float64
+	m2        float64
+	reservoir []float64
+}
+
+// add folds one duration sample (in milliseconds) into the summary
+func (o *onlineStats) add(ms float64) {
+	o.count++
+	if o.count == 1 || ms < o.min {
+		o.min = ms
+	}
+	if ms > o.max {
+		o.max = ms
+	}
+	delta := ms - o.mean
+	o.mean += delta / float64(o.count)
+	o.m2 += delta * (ms - o.mean)
+
+	if len(o.reservoir) < reservoirSize {
+		o.reservoir = append(o.reservoir, ms)
+	} else if slot := rand.Intn(o.count); slot < reservoirSize {
+		o.reservoir[slot] = ms
+	}
+}
+
+// stddev returns the population standard deviation of all samples seen
+func (o *onlineStats) stddev() float64 {
+	if o.count == 0 {
+		return 0
+	}
+	return math.Sqrt(o.m2 / float64(o.count))
+}
+
+// median estimates the median from the reservoir sample; exact when the
+// run fit within the reservoir
+func (o *onlineStats) median() float64 {
+	if len(o.reservoir) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(o.reservoir))
+	copy(sorted, o.reservoir)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// CommandSummary is the aggregated view of one command's results over a
+// bounded-memory run; durations are in milliseconds
+type CommandSummary struct {
+	Count        int
+	Min          float64
+	Max          float64
+	Avg          float64
+	Median       float64
+	Stddev       float64
+	Errors       int
+	Retries      int
+	ErrorClasses map[string]int
+}
+
+// RunSummary holds the per-command summaries and completed iteration count
+// of a run whose per-iteration statistics were aggregated rather than kept
+type RunSummary struct {
+	Iterations int
+	Commands   map[string]CommandSummary
+}
+
+// MergeSummaries combines two bounded-memory run summaries; variances are
+// merged with the parallel (Chan et al.) formula while the median keeps the
+// value from the side with more samples, an approximation noted here because
+// exact medians can't be combined from summaries alone
+func MergeSummaries(a, b *RunSummary) *RunSummary {
+	if a == nil {
+		return b
+	}
+	merged := &RunSummary{
+		Iterations: a.Iterations + b.Iterations,
+		Commands:   make(map[string]CommandSummary),
+	}
+	for key, cmd := range a.Commands {
+		merged.Commands[key] = cmd
+	}
+	for key, bCmd := range b.Commands {
+		aCmd, ok := merged.Commands[key]
+		if !ok {
+			merged.Commands[key] = bCmd
+			continue
+		}
+		total := aCmd.Count + bCmd.Count
+		delta := bCmd.Avg - aCmd.Avg
+		m2 := aCmd.Stddev*aCmd.Stddev*float64(aCmd.Count) +
+			bCmd.Stddev*bCmd.Stddev*float64(bCmd.Count) +
+			delta*delta*float64(aCmd.Count)*float64(bCmd.Count)/float64(total)
+		median := aCmd.Median
+		if bCmd.Count > aCmd.Count {
+			median = bCmd.Median
+		}
+		classes := make(map[string]int)
+		for class, count := range aCmd.ErrorClasses {
+			classes[class] += count
+		}
+		for class, count := range bCmd.ErrorClasses {
+			classes[class] += count
+		}
+		if len(classes) == 0 {
+			classes = nil
+		}
+		merged.Commands[key] = CommandSummary{
+			Count:        total,
+			Min:          math.Min(aCmd.Min, bCmd.Min),
+			Max:          math.Max(aCmd.Max, bCmd.Max),
+			Avg:          (aCmd.Avg*float64(aCmd.Count) + bCmd.Avg*float64(bCmd.Count)) / float64(total),
+			Median:       median,
+			Stddev:       math.Sqrt(m2 / float64(total)),
+			Errors:       aCmd.Errors + bCmd.Errors,
+			Retries:      aCmd.Retries + bCmd.Retries,
+			ErrorClasses: classes,
+		}
+	}
+	return merged
+}
+
+// statsAggregator folds iteration RunStatistics into per-command online
+// summaries as they stream from worker threads, so memory stays flat no
+// matter how many iterations a run performs
+type statsAggregator struct {
+	durations  map[string]*onlineStats
+	errors     map[string]int
+	retries    map[string]int
+	iterations int
+}
+
+func newStatsAggregator() *statsAggregator {
+	return &statsAggregator{
+		durations: make(map[string]*onlineStats),
+		errors:    make(map[string]int),
+		retries:   make(map[string]int),
+	}
+}
+
+// add folds one iteration's statistics into the aggregate
+func (a *statsAggregator) add(stat RunStatistics) {
+	a.iterations++
+	for key, duration := range stat.Durations {
+		online, ok := a.durations[key]
+		if !ok {
+			online = &onlineStats{}
+			a.durations[key] = online
+		}
+		online.add(float64(duration.Nanoseconds() / int64(time.Millisecond)))
+	}
+	for key, count := range stat.Errors {
+		a.errors[key] += count
+	}
+	for key, count := range stat.Retries {
+		a.retries[key] += count
+	}
+}
+
+// summary renders the aggregate into its exported form, splitting the
+// classified "<command>:<class>" error keys out into per-command breakdowns
+func (a *statsAggregator) summary() *RunSummary {
+	commands := make(map[string]CommandSummary, len(a.durations))
+	for key, online := range a.durations {
+		var errorClasses map[string]int
+		for ekey, count := range a.errors {
+			if strings.HasPrefix(ekey, key+":") {
+				if errorClasses == nil {
+					errorClasses = make(map[string]int)
+				}
+				errorClasses[strings.TrimPrefix(ekey, key+":")] = count
+			}
+		}
+		commands[key] = CommandSummary{
+			Count:        online.count,
+			Min:          online.min,
+			Max:          online.max,
+			Avg:          online.mean,
+			Median:       online.median(),
+			Stddev:       online.stddev(),
+			Errors:       a.errors[key],
+			Retries:      a.retries[key],
+			ErrorClasses: errorClasses,
+		}
+	}
+	return &RunSummary{
+		Iterations: a.iterations,
+		Commands:   commands,
+	}
+}
@@ -3,10 +3,15 @@ package benches
 import (
 	"context"
 	"fmt"
+	"math/rand"
+	"os"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/estesp/bucketbench/driver"
 	"github.com/estesp/bucketbench/stats"
+	"gopkg.in/yaml.v3"
 )
 
 // State represents the state of a benchmark object
@@ -21,6 +26,10 @@ type Type int
 type RunStatistics struct {
 	Durations map[string]time.Duration
 	Errors    map[string]int
+	// Retries counts per-command attempts beyond the first made by the
+	// optional retry policy; kept apart from Errors so retried-but-
+	// successful operations don't inflate error counts
+	Retries   map[string]int
 	Timestamp time.Time
 	Daemon    *stats.ProcMetrics
 }
@@ -28,27 +37,421 @@ type RunStatistics struct {
 // Benchmark is the object form of a YAML-defined custom benchmark
 // used to define the specific operations to perform
 type Benchmark struct {
-	Name     string
-	Image    string
-	Command  string // optionally override the default image CMD/ENTRYPOINT
-	RootFs   string
-	Detached bool
-	Drivers  []DriverConfig
-	Commands []string
+	Name string
+	// Profile names a fragment from the suite's 'profiles:' map (or an
+	// included file) whose fields this benchmark starts from; any field the
+	// benchmark sets itself overrides the fragment
+	Profile       string `yaml:"profile"`
+	Image         string
+	Command       CommandOverride // optionally override the default image CMD/ENTRYPOINT
+	RootFs        string
+	Detached      bool
+	Drivers       []DriverConfig
+	Commands      []string
+	CustomMetrics []CustomMetric `yaml:"customMetrics"`
+	// ExecCommand is the command run inside the container by the 'exec'
+	// benchmark command
+	ExecCommand string `yaml:"execCommand"`
+	// ReadyCheck optionally extends 'run' timing until the container is
+	// observed ready rather than just until the API/CLI call returns
+	ReadyCheck *ReadyCheck `yaml:"readyCheck"`
+	// Expect optionally asserts on container exit code and output after the
+	// run/wait commands, so functional regressions surface as errors in the
+	// benchmark results
+	Expect *Expectation `yaml:"expect"`
+	// Mounts lists structured bind mounts applied to every driver entry
+	// unless an entry declares its own
+	Mounts []Mount `yaml:"mounts"`
+	// PullPolicy controls image pulling for every driver entry unless an
+	// entry declares its own: "always" re-pulls on each create, "never"
+	// fails when the image is absent, "if-not-present" (the default) pulls
+	// only when missing
+	PullPolicy string `yaml:"pullPolicy"`
+	// Images lists images which iterations cycle through instead of the
+	// single Image, so runs reflect mixed-image workloads and exercise the
+	// snapshotter with diverse layer sets
+	Images []string `yaml:"images"`
+	// ImageOrder selects the rotation over Images: "round-robin" (the
+	// default) or "random"
+	ImageOrder string `yaml:"imageOrder"`
+	// DriverOrder selects the execution order of the driver entries:
+	// "sequential" (the default) or "random", which shuffles the entries on
+	// every pass so thermal drift and cache state don't systematically
+	// favor the first driver listed
+	DriverOrder string `yaml:"driverOrder"`
+	// Repetitions executes each driver entry this many times and reports
+	// the per-repetition rate spread plus statistics combined across every
+	// repetition; unset or 1 runs each entry once
+	Repetitions int `yaml:"repetitions"`
+}
+
+// Validate checks a decoded benchmark definition for missing or meaningless
+// values before any driver work starts, naming the offending field (e.g.
+// "drivers[1].iterations must be > 0") instead of silently running with zero
+// values. It also applies defaults: a driver entry without threads runs one
+// thread.
+func (b *Benchmark) Validate() error {
+	if b.Image == "" && b.RootFs == "" {
+		for i, config := range b.Drivers {
+			if config.Image == "" && config.RootFs == "" {
+				return fmt.Errorf("an 'image:' (or 'rootfs:') entry is required, either top-level or on drivers[%d]", i)
+			}
+		}
+		if len(b.Drivers) == 0 {
+			return fmt.Errorf("an 'image:' (or 'rootfs:') entry is required")
+		}
+	}
+	if len(b.Drivers) == 0 {
+		return fmt.Errorf("at least one 'drivers:' entry is required")
+	}
+	for i := range b.Drivers {
+		config := &b.Drivers[i]
+		if config.Type == "" {
+			return fmt.Errorf("drivers[%d].type is required", i)
+		}
+		if driver.StringToType(config.Type) == driver.Null {
+			return fmt.Errorf("drivers[%d].type %q is not a known driver type", i, config.Type)
+		}
+		if config.Threads < 0 {
+			return fmt.Errorf("drivers[%d].threads must be > 0", i)
+		}
+		if config.Threads == 0 {
+			config.Threads = 1
+		}
+		if config.Iterations <= 0 && config.Duration == "" {
+			return fmt.Errorf("drivers[%d].iterations must be > 0 (or set 'duration:')", i)
+		}
+	}
+	if len(b.Commands) == 0 {
+		return fmt.Errorf("at least one 'commands:' entry is required")
+	}
+	switch b.DriverOrder {
+	case "", driverOrderSequential, driverOrderRandom:
+	default:
+		return fmt.Errorf("invalid driverOrder %q; expected %q or %q",
+			b.DriverOrder, driverOrderSequential, driverOrderRandom)
+	}
+	return nil
+}
+
+// accepted 'driverOrder:' values
+const (
+	driverOrderSequential = "sequential"
+	driverOrderRandom     = "random"
+)
+
+// OrderedDrivers returns the benchmark's driver entries in the configured
+// execution order; with 'driverOrder: random' the entries are shuffled into
+// a fresh order on every call
+func (b *Benchmark) OrderedDrivers() []DriverConfig {
+	if b.DriverOrder != driverOrderRandom {
+		return b.Drivers
+	}
+	drivers := append([]DriverConfig(nil), b.Drivers...)
+	rand.Shuffle(len(drivers), func(i, j int) {
+		drivers[i], drivers[j] = drivers[j], drivers[i]
+	})
+	return drivers
+}
+
+// CommandOverride is the argv replacing the image's default CMD/ENTRYPOINT.
+// In YAML it accepts either a list (passed through verbatim, so arguments
+// containing spaces survive) or a plain string (split on whitespace for
+// backward compatibility).
+type CommandOverride []string
+
+// UnmarshalYAML accepts both the list and legacy string forms of command:
+func (c *CommandOverride) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.SequenceNode:
+		var argv []string
+		if err := value.Decode(&argv); err != nil {
+			return err
+		}
+		*c = argv
+	case yaml.ScalarNode:
+		var cmd string
+		if err := value.Decode(&cmd); err != nil {
+			return err
+		}
+		*c = strings.Fields(cmd)
+	default:
+		return fmt.Errorf("command: must be a string or a list of arguments")
+	}
+	return nil
+}
+
+// Mount is the YAML form of one structured bind mount attached to benchmark
+// containers
+type Mount struct {
+	Source      string `yaml:"source"`
+	Destination string `yaml:"destination"`
+	ReadOnly    bool   `yaml:"readonly"`
+}
+
+// Resources is the YAML form of per-container resource limits; zero or
+// omitted values leave the corresponding limit unset
+type Resources struct {
+	// CPUShares is the relative CPU weight versus other containers
+	CPUShares int64 `yaml:"cpuShares"`
+	// CPUQuota is the CFS quota in microseconds per 100ms period
+	CPUQuota int64 `yaml:"cpuQuota"`
+	// MemoryMB is the memory limit in megabytes
+	MemoryMB int64 `yaml:"memoryMB"`
+}
+
+// RegistryAuthConfig configures credentials for image pulls from private
+// registries. An explicit username/password pair takes precedence; otherwise
+// the REGISTRY_USERNAME/REGISTRY_PASSWORD environment variables are used, and
+// useDockerConfig falls back to the stored Docker CLI credentials for the
+// image's registry.
+type RegistryAuthConfig struct {
+	Username        string `yaml:"username"`
+	Password        string `yaml:"password"`
+	UseDockerConfig bool   `yaml:"useDockerConfig"`
+}
+
+// ReadyCheck defines how a started container is determined to be "ready":
+// either a regex matched against the container log output or a probe command
+// exec'd in the container until it succeeds. The run duration then reflects
+// user-perceived startup latency for service-style workloads.
+type ReadyCheck struct {
+	LogPattern string `yaml:"logPattern"`
+	Probe      string `yaml:"probe"`
+	TimeoutSec int    `yaml:"timeoutSec"`
+}
+
+// Expectation defines assertions checked during the benchmark: an expected
+// exit code (verified after 'wait') and/or expected stdout content (substring
+// or regex, verified against 'run' output). Failed assertions are counted as
+// errors for the corresponding command.
+type Expectation struct {
+	ExitCode       *int   `yaml:"exitCode"`
+	StdoutContains string `yaml:"stdoutContains"`
+	StdoutPattern  string `yaml:"stdoutPattern"`
+}
+
+// CustomMetric defines a regex-based extraction of a numeric millisecond
+// value from container output (e.g. an in-container timing harness printing
+// its own startup-to-ready time), merged into RunStatistics as a named
+// duration alongside the driver-measured command timings
+type CustomMetric struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
 }
 
 // DriverConfig contains the YAML-defined parameters for running a
 // benchmark against a specific driver type
 type DriverConfig struct {
-	Type             string
-	ClientPath       string // optional path to specific client binary/socket
-	Threads          int
-	Iterations       int
-	LogDriver        string            `yaml:"logDriver"`
-	LogOpts          map[string]string `yaml:"logOpts"`
-	CGroupPath       string            `yaml:"cgroupPath"`
-	StreamStats      bool              `yaml:"streamStats"`
-	StatsIntervalSec int               `yaml:"statsIntervalSec"`
+	Type       string
+	ClientPath string // optional path to specific client binary/socket
+	Threads    int
+	Iterations int
+	// Duration is an alternative to Iterations: each thread loops the
+	// command list until the parsed deadline (e.g. "5m") has passed, making
+	// total operations an output of the run rather than an input
+	Duration   string            `yaml:"duration"`
+	LogDriver  string            `yaml:"logDriver"`
+	LogOpts    map[string]string `yaml:"logOpts"`
+	CGroupPath string            `yaml:"cgroupPath"`
+	// MemoryMetric selects how daemon memory is accounted when walking the
+	// process tree: "rss" (default), "pss", or "uss"; PSS avoids RSS's
+	// double-count of pages shared across shim processes
+	MemoryMetric     string `yaml:"memoryMetric"`
+	StreamStats      bool   `yaml:"streamStats"`
+	StatsIntervalSec int    `yaml:"statsIntervalSec"`
+	// optional bounds for the adaptive overhead sampling interval; when unset
+	// the overhead benchmark uses its built-in defaults
+	SampleMinIntervalMs int `yaml:"sampleMinIntervalMs"`
+	SampleMaxIntervalMs int `yaml:"sampleMaxIntervalMs"`
+	// IdleBaselineSec samples the idle daemon for this many seconds before
+	// the overhead benchmark starts and reports usage as delta-from-idle,
+	// so pre-existing daemon footprint isn't attributed to the workload
+	IdleBaselineSec int `yaml:"idleBaselineSec"`
+	// optional think-time between commands and between iterations; a
+	// non-zero jitter adds a random delay in [0,jitter) to each sleep
+	CommandDelayMs   int `yaml:"commandDelayMs"`
+	IterationDelayMs int `yaml:"iterationDelayMs"`
+	DelayJitterMs    int `yaml:"delayJitterMs"`
+	// optional ramp-up period over which worker threads are started
+	// staggered rather than all at once
+	RampUpSec int `yaml:"rampUpSec"`
+	// optional open-loop target rate (iterations/sec across all threads);
+	// iterations are paced by a token bucket rather than run back-to-back
+	TargetRate float64 `yaml:"targetRate"`
+	// optional cap on driver operations in flight across all threads, so
+	// runs can model "N workers but at most M concurrent API calls" and
+	// avoid unintentionally overloading a daemon
+	MaxInflight int `yaml:"maxInflight"`
+	// PodBench enables the CRI pod-level lifecycle mode, timing sandbox and
+	// container operations as distinct stat keys
+	PodBench bool `yaml:"podBench"`
+	// Density is the target number of concurrently running containers
+	// maintained by the density benchmark
+	Density int `yaml:"density"`
+	// RestartUnit is the systemd unit restarted by the restart benchmark;
+	// a default is chosen from the driver type ("docker", "containerd")
+	// when unset
+	RestartUnit string `yaml:"restartUnit"`
+	// ColdDaemon restarts the daemon's systemd unit before the measured
+	// run begins, so comparisons start from a freshly started daemon
+	// rather than one warmed by previous runs (explicit opt-in)
+	ColdDaemon bool `yaml:"coldDaemon"`
+	// BoundedStats folds per-iteration statistics into online aggregators
+	// as they stream from worker threads, keeping harness memory flat over
+	// very long runs at the cost of estimated (reservoir-sampled) medians
+	BoundedStats bool `yaml:"boundedStats"`
+	// RawStatsFile optionally streams the raw per-iteration statistics as
+	// JSON lines to the named file during a bounded-stats run
+	RawStatsFile string `yaml:"rawStatsFile"`
+	// Chaos enables the optional fault injector, which kills shims or
+	// restarts the daemon at random points during the run
+	Chaos *ChaosConfig `yaml:"chaos"`
+	// ColdCache removes the benchmark image between iterations so every run
+	// measures cold-start behavior (supported drivers only)
+	ColdCache bool `yaml:"coldCache"`
+	// Network names a real (bridge/CNI) network for benchmark containers;
+	// drivers which can decompose the attach report network setup time as a
+	// distinct stat key
+	Network string `yaml:"network"`
+	// Volumes lists bind mounts and named volumes ("source:dest" form) to
+	// attach to benchmark containers
+	Volumes []string `yaml:"volumes"`
+	// Mounts lists structured bind mounts with a read-only flag, for
+	// workloads needing data or sockets from the host
+	Mounts []Mount `yaml:"mounts"`
+	// Resources optionally constrains benchmark containers (cpu shares,
+	// CFS quota, memory limit)
+	Resources *Resources `yaml:"resources"`
+	// Privileged runs benchmark containers in privileged mode
+	Privileged bool `yaml:"privileged"`
+	// CapAdd/CapDrop adjust container capabilities; names are accepted
+	// with or without the CAP_ prefix
+	CapAdd  []string `yaml:"capAdd"`
+	CapDrop []string `yaml:"capDrop"`
+	// AutoRemove removes benchmark containers on exit (docker run --rm);
+	// Docker drivers only
+	AutoRemove bool `yaml:"autoRemove"`
+	// RestartPolicy sets the Docker restart policy for benchmark
+	// containers (e.g. "no", "on-failure:2", "unless-stopped")
+	RestartPolicy string `yaml:"restartPolicy"`
+	// Runtime selects an alternative OCI runtime registered with the
+	// Docker daemon (e.g. "kata", "runsc", "youki")
+	Runtime string `yaml:"runtime"`
+	// WaitHealthy times `run` until the container's HEALTHCHECK reports
+	// healthy rather than just started; Docker API driver only
+	WaitHealthy bool `yaml:"waitHealthy"`
+	// DockerHost points the Docker API driver at a remote daemon URL
+	// (e.g. "tcp://10.0.0.5:2376"); DOCKER_HOST applies when unset
+	DockerHost string `yaml:"dockerHost"`
+	// DockerTLSCertPath names a directory with ca.pem/cert.pem/key.pem
+	// for TLS connections to a remote Docker daemon
+	DockerTLSCertPath string `yaml:"dockerTLSCertPath"`
+	// ContainerdTLSCertPath names a directory with ca.pem/cert.pem/key.pem
+	// used when clientPath is a tcp:// URL to a remote containerd
+	ContainerdTLSCertPath string `yaml:"containerdTLSCertPath"`
+	// EventTimings subscribes to the engine's event stream and records the
+	// gap between API returns and the matching asynchronous events as
+	// extra stat keys: 'taskStartEvent'/'taskExitEvent' for containerd,
+	// 'createEvent'/'startEvent'/'dieEvent' for the Docker API driver
+	EventTimings bool `yaml:"eventTimings"`
+	// RegistryAuth supplies credentials for pulling the benchmark image
+	// from a private registry
+	RegistryAuth *RegistryAuthConfig `yaml:"registryAuth"`
+	// Platform pins image pulls to a platform (e.g. "linux/arm64") so
+	// multi-arch references resolve deterministically
+	Platform string `yaml:"platform"`
+	// PullPolicy overrides the benchmark-level pull policy for this driver
+	// entry ("always", "if-not-present", or "never")
+	PullPolicy string `yaml:"pullPolicy"`
+	// Image overrides the benchmark-level image for this driver entry, so
+	// one benchmark can compare image variants across engines
+	Image string `yaml:"image"`
+	// Images lists images which iterations cycle through instead of the
+	// single image (see the benchmark-level field of the same name)
+	Images []string `yaml:"images"`
+	// ImageOrder selects the rotation over Images: "round-robin" (the
+	// default) or "random"
+	ImageOrder string `yaml:"imageOrder"`
+	// RootFs overrides the benchmark-level exploded rootfs path for this
+	// driver entry (used by the runc, crun, youki, and ctr drivers)
+	RootFs string `yaml:"rootfs"`
+	// StopSignal overrides the signal used by the stop command (e.g.
+	// "SIGTERM" for graceful shutdown instead of each driver's default)
+	StopSignal string `yaml:"stopSignal"`
+	// StopTimeoutSec is the grace period allowed after the stop signal
+	// before the container is forcibly killed
+	StopTimeoutSec int `yaml:"stopTimeoutSec"`
+	// ContainerdNamespace overrides the containerd namespace ("bb" by
+	// default) used for benchmark containers
+	ContainerdNamespace string `yaml:"containerdNamespace"`
+	// SandboxConfig/ContainerConfig point the CRI driver at custom pod
+	// sandbox/container config JSON templates; built-in defaults apply
+	// when unset
+	SandboxConfig   string `yaml:"sandboxConfig"`
+	ContainerConfig string `yaml:"containerConfig"`
+	// SandboxReuse keeps one CRI pod sandbox per thread and churns
+	// containers inside it, instead of one pod per container
+	SandboxReuse bool `yaml:"sandboxReuse"`
+	// PauseImage overrides the pod sandbox (pause) image used by the CRI
+	// driver (registry.k8s.io/pause:3.9 by default)
+	PauseImage string `yaml:"pauseImage"`
+	// SystemdCgroup passes --systemd-cgroup to the OCI-binary drivers
+	// (runc/crun/youki) to use the systemd cgroup manager
+	SystemdCgroup bool `yaml:"systemdCgroup"`
+	// RuntimeRoot passes --root to the OCI-binary drivers to use a
+	// custom runtime state directory
+	RuntimeRoot string `yaml:"runtimeRoot"`
+	// BundleIsolation gives each benchmark thread its own copy of the
+	// OCI bundle for the runc-class drivers, so concurrent containers
+	// don't share one mutable rootfs
+	BundleIsolation bool `yaml:"bundleIsolation"`
+	// ContainerMetrics enables the per-container resource collector, which
+	// samples each benchmark container's CPU/memory during the run and
+	// reports aggregate container-side usage (supported drivers only)
+	ContainerMetrics bool `yaml:"containerMetrics"`
+	// TimeFirstProcess records a 'firstProcess' stat measuring from run
+	// invocation until the container's init process appears in its cgroup,
+	// since API return and true process start can diverge (Linux only)
+	TimeFirstProcess bool `yaml:"timeFirstProcess"`
+	// TraceSyscalls enables the eBPF syscall collector, which counts
+	// clone/execve and selected syscalls issued by the daemon and shim
+	// processes during the run (Linux only, requires bpftrace and root)
+	TraceSyscalls bool `yaml:"traceSyscalls"`
+	// Profile optionally wraps a named benchmark command with perf or
+	// strace attached to the daemon for a sampled subset of iterations,
+	// saving per-iteration output files for offline analysis
+	Profile *ProfileHook `yaml:"profile"`
+	// ClientPerThread opens one engine client connection per worker thread
+	// (e.g. one containerd gRPC connection each) instead of sharing a
+	// single client, so users can measure whether client-connection
+	// multiplexing is the bottleneck at high thread counts
+	ClientPerThread bool `yaml:"clientPerThread"`
+	// UniqueNamespace generates a unique containerd namespace per run so
+	// concurrent bucketbench invocations (or other users of the default
+	// namespace) aren't affected by this run's cleanup
+	UniqueNamespace bool `yaml:"uniqueNamespace"`
+	// CommandTimeoutSec bounds each driver operation with a context
+	// deadline; operations exceeding it are recorded as timeout errors
+	// rather than hanging the worker thread
+	CommandTimeoutSec int `yaml:"commandTimeoutSec"`
+	// RetryAttempts enables retrying failed driver operations up to the
+	// given number of extra attempts, with a linearly increasing backoff
+	// of RetryBackoffMs between attempts
+	RetryAttempts  int `yaml:"retryAttempts"`
+	RetryBackoffMs int `yaml:"retryBackoffMs"`
+	// filled in from the benchmark-level customMetrics definition before
+	// benchmark creation
+	CustomMetrics []CustomMetric `yaml:"-"`
+	// filled in from the benchmark-level execCommand definition before
+	// benchmark creation
+	ExecCommand string `yaml:"-"`
+	// filled in from the benchmark-level readyCheck definition before
+	// benchmark creation
+	ReadyCheck *ReadyCheck `yaml:"-"`
+	// filled in from the benchmark-level expect definition before
+	// benchmark creation
+	Expect *Expectation `yaml:"-"`
 }
 
 // State constants
@@ -70,13 +473,22 @@ const (
 	Custom
 	// Benchmark daemon cpu/memory usage
 	Overhead
+	// Density ramps up to and maintains a target number of concurrently
+	// running containers, churn-replacing containers at steady state
+	Density
+	// Restart starts a population of containers, restarts the engine
+	// daemon, and measures time until all containers are reconciled
+	Restart
+	// Longevity runs continuous churn while tracking the growth slope of
+	// daemon memory and process counts to surface leaks
+	Longevity
 )
 
 // Bench is an interface to manage benchmark execution against a specific driver
 type Bench interface {
 	// Init initializes the benchmark (for example, verifies a daemon is running for daemon-centric
 	// engines, pre-pulls images, etc.)
-	Init(ctx context.Context, name string, driverType driver.Type, binaryPath, imageInfo, cmdOverride string, trace bool) error
+	Init(ctx context.Context, name string, driverType driver.Type, binaryPath, imageInfo string, cmdOverride []string, trace bool) error
 
 	// Validates the any condition that need to be checked before actual banchmark run.
 	// Helpful in testing operations required in benchmark for single run.
@@ -90,6 +502,10 @@ type Bench interface {
 	// Stats returns the statistics of the benchmark run
 	Stats() []RunStatistics
 
+	// Summary returns the bounded-memory aggregation of the run's
+	// iteration statistics, or nil when raw statistics were retained
+	Summary() *RunSummary
+
 	// Elapsed returns the time.Duration that the benchmark took to execute
 	Elapsed() time.Duration
 
@@ -103,6 +519,23 @@ type Bench interface {
 	Info(ctx context.Context) (string, error)
 }
 
+// daemonUnit resolves the systemd unit controlling the configured driver's
+// daemon, preferring an explicit restartUnit entry over the per-driver
+// default; daemonless (OCI binary) drivers have no unit to control
+func daemonUnit(config *DriverConfig) (string, error) {
+	if config.RestartUnit != "" {
+		return config.RestartUnit, nil
+	}
+	switch driver.StringToType(config.Type) {
+	case driver.DockerCLI, driver.Docker:
+		return "docker", nil
+	case driver.Containerd, driver.Ctr, driver.CRI:
+		return "containerd", nil
+	default:
+		return "", fmt.Errorf("driver %q has no daemon unit to control", config.Type)
+	}
+}
+
 // New creates an instance of the selected benchmark type
 func New(benchType Type, config *DriverConfig) (Bench, error) {
 	switch benchType {
@@ -111,20 +544,190 @@ func New(benchType Type, config *DriverConfig) (Bench, error) {
 			state: Created,
 		}, nil
 
-	case Custom, Overhead:
+	case Custom, Overhead, Density, Restart, Longevity:
 		if config.StatsIntervalSec == 0 {
 			config.StatsIntervalSec = 1
 		}
 
 		statsInterval := time.Duration(config.StatsIntervalSec) * time.Second
 
+		customMetrics, err := compileCustomMetrics(config.CustomMetrics)
+		if err != nil {
+			return nil, err
+		}
+
+		var readyLogPattern *regexp.Regexp
+		if config.ReadyCheck != nil && config.ReadyCheck.LogPattern != "" {
+			readyLogPattern, err = regexp.Compile(config.ReadyCheck.LogPattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid readyCheck logPattern %q: %v", config.ReadyCheck.LogPattern, err)
+			}
+		}
+
+		var expectPattern *regexp.Regexp
+		if config.Expect != nil && config.Expect.StdoutPattern != "" {
+			expectPattern, err = regexp.Compile(config.Expect.StdoutPattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid expect stdoutPattern %q: %v", config.Expect.StdoutPattern, err)
+			}
+		}
+
+		if config.Profile != nil {
+			if config.Profile.Tool != "perf" && config.Profile.Tool != "strace" {
+				return nil, fmt.Errorf("invalid profile tool %q: must be \"perf\" or \"strace\"", config.Profile.Tool)
+			}
+			if config.Profile.Command == "" {
+				return nil, fmt.Errorf("profile hook requires a 'command:' entry naming the command to wrap")
+			}
+		}
+
+		var runDuration time.Duration
+		if config.Duration != "" {
+			runDuration, err = time.ParseDuration(config.Duration)
+			if err != nil {
+				return nil, fmt.Errorf("invalid benchmark duration %q: %v", config.Duration, err)
+			}
+		}
+
+		namespace := config.ContainerdNamespace
+		if config.UniqueNamespace {
+			namespace = fmt.Sprintf("bb-%d-%d", os.Getpid(), time.Now().UnixNano())
+		}
+
+		if config.Chaos != nil {
+			if err := validateChaos(config.Chaos); err != nil {
+				return nil, err
+			}
+		}
+
+		var driverMounts []driver.Mount
+		for _, mount := range config.Mounts {
+			if mount.Source == "" || mount.Destination == "" {
+				return nil, fmt.Errorf("mounts entries require both 'source:' and 'destination:'")
+			}
+			if !strings.HasPrefix(mount.Source, "/") {
+				return nil, fmt.Errorf("mount source %q must be an absolute host path", mount.Source)
+			}
+			driverMounts = append(driverMounts, driver.Mount{
+				Source:      mount.Source,
+				Destination: mount.Destination,
+				ReadOnly:    mount.ReadOnly,
+			})
+		}
+
+		switch config.ImageOrder {
+		case "", imageOrderRoundRobin, imageOrderRandom:
+		default:
+			return nil, fmt.Errorf("invalid imageOrder %q; expected %q or %q",
+				config.ImageOrder, imageOrderRoundRobin, imageOrderRandom)
+		}
+
+		switch config.PullPolicy {
+		case "", driver.PullAlways, driver.PullIfNotPresent, driver.PullNever:
+		default:
+			return nil, fmt.Errorf("invalid pullPolicy %q; expected %q, %q, or %q",
+				config.PullPolicy, driver.PullAlways, driver.PullIfNotPresent, driver.PullNever)
+		}
+
+		var driverResources *driver.Resources
+		if config.Resources != nil {
+			res := config.Resources
+			if res.CPUShares < 0 || res.CPUQuota < 0 || res.MemoryMB < 0 {
+				return nil, fmt.Errorf("resources limits must not be negative")
+			}
+			driverResources = &driver.Resources{
+				CPUShares:   res.CPUShares,
+				CPUQuota:    res.CPUQuota,
+				MemoryBytes: res.MemoryMB * 1024 * 1024,
+			}
+		}
+
+		var registryAuth *driver.RegistryAuth
+		if config.RegistryAuth != nil {
+			registryAuth = &driver.RegistryAuth{
+				Username:        config.RegistryAuth.Username,
+				Password:        config.RegistryAuth.Password,
+				UseDockerConfig: config.RegistryAuth.UseDockerConfig,
+			}
+		}
+
+		// daemon control (cold-daemon restarts, daemon-restart chaos, the
+		// restart benchmark) needs the systemd unit behind the configured
+		// driver
+		var unit string
+		if config.ColdDaemon || benchType == Restart ||
+			(config.Chaos != nil && config.Chaos.Mode == chaosRestartDaemon) {
+			unit, err = daemonUnit(config)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		custom := CustomBench{
-			state: Created,
+			state:           Created,
+			customMetrics:   customMetrics,
+			commandTimeout:  time.Duration(config.CommandTimeoutSec) * time.Second,
+			retryAttempts:   config.RetryAttempts,
+			retryBackoff:    time.Duration(config.RetryBackoffMs) * time.Millisecond,
+			commandDelay:    time.Duration(config.CommandDelayMs) * time.Millisecond,
+			iterationDelay:  time.Duration(config.IterationDelayMs) * time.Millisecond,
+			delayJitter:     time.Duration(config.DelayJitterMs) * time.Millisecond,
+			rampUp:          time.Duration(config.RampUpSec) * time.Second,
+			runDuration:     runDuration,
+			targetRate:      config.TargetRate,
+			maxInflight:     config.MaxInflight,
+			execCommand:     config.ExecCommand,
+			clientPerThread: config.ClientPerThread,
+			ctrMetricsOn:    config.ContainerMetrics,
+			firstProcOn:     config.TimeFirstProcess,
+			traceSyscalls:   config.TraceSyscalls,
+			profile:         config.Profile,
+			coldDaemon:      config.ColdDaemon,
+			daemonUnit:      unit,
+			boundedStats:    config.BoundedStats,
+			rawStatsFile:    config.RawStatsFile,
+			chaos:           config.Chaos,
+			images:          config.Images,
+			imageRandom:     config.ImageOrder == imageOrderRandom,
+			readyCheck:      config.ReadyCheck,
+			readyLogPattern: readyLogPattern,
+			expect:          config.Expect,
+			expectPattern:   expectPattern,
 			Config: driver.Config{
-				LogDriver:     config.LogDriver,
-				LogOpts:       config.LogOpts,
-				StreamStats:   config.StreamStats,
-				StatsInterval: statsInterval,
+				LogDriver:             config.LogDriver,
+				LogOpts:               config.LogOpts,
+				StreamStats:           config.StreamStats,
+				StatsInterval:         statsInterval,
+				PodBench:              config.PodBench,
+				ColdCache:             config.ColdCache,
+				Network:               config.Network,
+				Volumes:               config.Volumes,
+				Mounts:                driverMounts,
+				Resources:             driverResources,
+				Privileged:            config.Privileged,
+				CapAdd:                config.CapAdd,
+				AutoRemove:            config.AutoRemove,
+				RestartPolicy:         config.RestartPolicy,
+				Runtime:               config.Runtime,
+				WaitHealthy:           config.WaitHealthy,
+				DockerHost:            config.DockerHost,
+				DockerTLSCertPath:     config.DockerTLSCertPath,
+				ContainerdTLSCertPath: config.ContainerdTLSCertPath,
+				EventTimings:          config.EventTimings,
+				CapDrop:               config.CapDrop,
+				RegistryAuth:          registryAuth,
+				Platform:              config.Platform,
+				PullPolicy:            config.PullPolicy,
+				StopSignal:            config.StopSignal,
+				StopTimeout:           time.Duration(config.StopTimeoutSec) * time.Second,
+				ContainerdNamespace:   namespace,
+				SandboxConfigPath:     config.SandboxConfig,
+				ContainerConfigPath:   config.ContainerConfig,
+				SandboxReuse:          config.SandboxReuse,
+				PauseImage:            config.PauseImage,
+				SystemdCgroup:         config.SystemdCgroup,
+				RuntimeRoot:           config.RuntimeRoot,
+				BundleIsolation:       config.BundleIsolation,
 			},
 		}
 
@@ -132,7 +735,44 @@ func New(benchType Type, config *DriverConfig) (Bench, error) {
 			return &custom, nil
 		}
 
-		return &OverheadBench{CustomBench: &custom, cgroupPath: config.CGroupPath}, nil
+		if benchType == Density {
+			if config.Density <= 0 {
+				return nil, fmt.Errorf("density benchmark requires a positive 'density:' value in the driver config")
+			}
+			return &DensityBench{CustomBench: &custom, density: config.Density}, nil
+		}
+
+		if benchType == Restart {
+			if config.Density <= 0 {
+				return nil, fmt.Errorf("restart benchmark requires a positive 'density:' value in the driver config")
+			}
+			return &RestartBench{CustomBench: &custom, count: config.Density}, nil
+		}
+
+		minInterval := overheadMinSampleInterval
+		if config.SampleMinIntervalMs > 0 {
+			minInterval = time.Duration(config.SampleMinIntervalMs) * time.Millisecond
+		}
+		maxInterval := overheadMaxSampleInterval
+		if config.SampleMaxIntervalMs > 0 {
+			maxInterval = time.Duration(config.SampleMaxIntervalMs) * time.Millisecond
+		}
+		if maxInterval < minInterval {
+			return nil, fmt.Errorf("invalid sampling interval bounds: max (%v) less than min (%v)", maxInterval, minInterval)
+		}
+
+		overheadBench := &OverheadBench{
+			CustomBench: &custom,
+			cgroupPath:  config.CGroupPath,
+			memMetric:   config.MemoryMetric,
+			minInterval: minInterval,
+			maxInterval: maxInterval,
+			idlePeriod:  time.Duration(config.IdleBaselineSec) * time.Second,
+		}
+		if benchType == Longevity {
+			return &LongevityBench{OverheadBench: overheadBench}, nil
+		}
+		return overheadBench, nil
 	default:
 		return nil, fmt.Errorf("no such benchmark type: %v", benchType)
 	}
@@ -146,6 +786,12 @@ func (b Type) String() string {
 		return "Custom"
 	case Overhead:
 		return "Overhead"
+	case Density:
+		return "Density"
+	case Restart:
+		return "Restart"
+	case Longevity:
+		return "Longevity"
 	default:
 		return "Unknown"
 	}
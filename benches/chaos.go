@@ -0,0 +1,219 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package benches
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/estesp/bucketbench/driver"
+	"github.com/estesp/bucketbench/utils"
+	"github.com/shirou/gopsutil/process"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// chaos injection modes
+	chaosKillShim      = "kill-shim"
+	chaosRestartDaemon = "restart-daemon"
+
+	// chaosRecoveryStatKey is the stat key recovery durations are recorded
+	// under, so injections appear alongside command timings in the report
+	chaosRecoveryStatKey = "chaosRecovery"
+
+	chaosDefaultMinInterval = 30 * time.Second
+	chaosDefaultMaxInterval = 2 * time.Minute
+	chaosRecoveryTimeout    = 2 * time.Minute
+	chaosRecoveryPoll       = 500 * time.Millisecond
+)
+
+// ChaosConfig is the YAML form of the optional fault injector: at random
+// points within the interval bounds a fault is injected (a shim process
+// killed or the daemon restarted) and the time until the daemon answers
+// again is recorded, so runtime resilience can be compared rather than just
+// happy-path speed
+type ChaosConfig struct {
+	// Mode selects the fault: "kill-shim" or "restart-daemon"
+	Mode           string `yaml:"mode"`
+	MinIntervalSec int    `yaml:"minIntervalSec"`
+	MaxIntervalSec int    `yaml:"maxIntervalSec"`
+}
+
+// validateChaos checks the injector configuration at benchmark construction
+func validateChaos(config *ChaosConfig) error {
+	switch config.Mode {
+	case chaosKillShim, chaosRestartDaemon:
+	default:
+		return fmt.Errorf("chaos mode must be %q or %q; got %q", chaosKillShim, chaosRestartDaemon, config.Mode)
+	}
+	if config.MinIntervalSec < 0 || config.MaxIntervalSec < 0 {
+		return fmt.Errorf("chaos interval bounds must not be negative")
+	}
+	if config.MaxIntervalSec > 0 && config.MaxIntervalSec < config.MinIntervalSec {
+		return fmt.Errorf("chaos maxIntervalSec (%d) less than minIntervalSec (%d)", config.MaxIntervalSec, config.MinIntervalSec)
+	}
+	return nil
+}
+
+// chaosInjector injects faults on a random schedule during a run and records
+// one RunStatistics entry per injection with the observed recovery time
+type chaosInjector struct {
+	config *ChaosConfig
+	driver driver.Driver
+	unit   string
+
+	stopC chan struct{}
+	doneC chan struct{}
+
+	mu      sync.Mutex
+	records []RunStatistics
+}
+
+func newChaosInjector(config *ChaosConfig, drv driver.Driver, unit string) *chaosInjector {
+	return &chaosInjector{
+		config: config,
+		driver: drv,
+		unit:   unit,
+		stopC:  make(chan struct{}),
+		doneC:  make(chan struct{}),
+	}
+}
+
+// start launches the injection loop; faults fire at uniformly random points
+// within the configured interval bounds until stop is called
+func (ci *chaosInjector) start(ctx context.Context) {
+	minInterval := chaosDefaultMinInterval
+	if ci.config.MinIntervalSec > 0 {
+		minInterval = time.Duration(ci.config.MinIntervalSec) * time.Second
+	}
+	maxInterval := chaosDefaultMaxInterval
+	if ci.config.MaxIntervalSec > 0 {
+		maxInterval = time.Duration(ci.config.MaxIntervalSec) * time.Second
+	}
+	if maxInterval < minInterval {
+		maxInterval = minInterval
+	}
+	go func() {
+		defer close(ci.doneC)
+		for {
+			wait := minInterval
+			if spread := maxInterval - minInterval; spread > 0 {
+				wait += time.Duration(rand.Int63n(int64(spread)))
+			}
+			select {
+			case <-ci.stopC:
+				return
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			ci.inject(ctx)
+		}
+	}()
+}
+
+// stop ends the injection loop and returns the per-injection records
+func (ci *chaosInjector) stop() []RunStatistics {
+	close(ci.stopC)
+	<-ci.doneC
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	return ci.records
+}
+
+// inject performs one fault and records the time until the daemon responds
+// to an info query again; a recovery timeout is recorded as an error so
+// unrecovered faults count against the run
+func (ci *chaosInjector) inject(ctx context.Context) {
+	switch ci.config.Mode {
+	case chaosKillShim:
+		if err := killRandomShim(); err != nil {
+			log.Warnf("chaos: shim kill failed: %v", err)
+			return
+		}
+	case chaosRestartDaemon:
+		controller, err := utils.NewSystemdController(ctx, ci.unit)
+		if err != nil {
+			log.Warnf("chaos: can't connect to systemd: %v", err)
+			return
+		}
+		_, err = controller.Restart(ctx)
+		controller.Close()
+		if err != nil {
+			log.Warnf("chaos: daemon restart failed: %v", err)
+			return
+		}
+	}
+
+	start := time.Now()
+	record := RunStatistics{
+		Durations: make(map[string]time.Duration),
+		Errors:    make(map[string]int),
+		Timestamp: start,
+	}
+	deadline := start.Add(chaosRecoveryTimeout)
+	for {
+		if _, err := ci.driver.Info(ctx); err == nil {
+			recovery := time.Since(start)
+			record.Durations[chaosRecoveryStatKey] = recovery
+			log.Infof("chaos: %s injected; daemon recovered in %v", ci.config.Mode, recovery)
+			break
+		}
+		if time.Now().After(deadline) {
+			record.Durations[chaosRecoveryStatKey] = chaosRecoveryTimeout
+			record.Errors[chaosRecoveryStatKey] = 1
+			log.Warnf("chaos: daemon did not recover within %v of %s injection", chaosRecoveryTimeout, ci.config.Mode)
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(chaosRecoveryPoll):
+		}
+	}
+
+	ci.mu.Lock()
+	ci.records = append(ci.records, record)
+	ci.mu.Unlock()
+}
+
+// killRandomShim SIGKILLs one randomly chosen shim process, approximating a
+// shim crash; an error is returned when no shim processes are found
+func killRandomShim() error {
+	procs, err := process.Processes()
+	if err != nil {
+		return err
+	}
+	var shims []*process.Process
+	for _, proc := range procs {
+		name, err := proc.Name()
+		if err != nil {
+			continue
+		}
+		if strings.Contains(name, "shim") {
+			shims = append(shims, proc)
+		}
+	}
+	if len(shims) == 0 {
+		return fmt.Errorf("no shim processes found")
+	}
+	victim := shims[rand.Intn(len(shims))]
+	log.Infof("chaos: killing shim process %d", victim.Pid)
+	return victim.Kill()
+}
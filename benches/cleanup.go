@@ -0,0 +1,79 @@
+package benches
+
+import (
+	"context"
+	"sync"
+
+	"github.com/estesp/bucketbench/driver"
+	log "github.com/sirupsen/logrus"
+)
+
+// trackedResource pairs a created container with the driver handle that
+// created it, since per-thread clients mean threads may hold distinct
+// driver instances
+type trackedResource struct {
+	drv driver.Driver
+	ctr driver.Container
+}
+
+// cleanupRegistry tracks every container (and, through driver removal,
+// any pod sandbox) a run creates, so a run-scoped sweep can remove
+// whatever is left behind even when a worker thread panics or a driver
+// errors mid-run, and report the resources that could not be removed
+type cleanupRegistry struct {
+	mu        sync.Mutex
+	resources map[string]trackedResource
+}
+
+func newCleanupRegistry() *cleanupRegistry {
+	return &cleanupRegistry{resources: make(map[string]trackedResource)}
+}
+
+// track records a freshly created container for the end-of-run sweep;
+// a nil registry (benches that never create containers) is a no-op
+func (r *cleanupRegistry) track(drv driver.Driver, ctr driver.Container) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.resources[ctr.Name()] = trackedResource{drv: drv, ctr: ctr}
+	r.mu.Unlock()
+}
+
+// release drops a container the benchmark itself removed successfully
+func (r *cleanupRegistry) release(name string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	delete(r.resources, name)
+	r.mu.Unlock()
+}
+
+// sweep stops and removes everything still tracked and returns the names
+// of resources that could not be removed. The caller passes a context
+// independent of the run's (usually context.Background()) so cleanup
+// still proceeds after a signal cancels the run.
+func (r *cleanupRegistry) sweep(ctx context.Context) []string {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	resources := r.resources
+	r.resources = make(map[string]trackedResource)
+	r.mu.Unlock()
+
+	var leftover []string
+	for name, res := range resources {
+		// best-effort stop first; an already-exited container just errors
+		// here and proceeds to removal
+		if _, _, err := res.drv.Stop(ctx, res.ctr); err != nil {
+			log.Debugf("cleanup: stop of %q: %v", name, err)
+		}
+		if _, _, err := res.drv.Remove(ctx, res.ctr); err != nil {
+			log.Warnf("cleanup: could not remove %q: %v", name, err)
+			leftover = append(leftover, name)
+		}
+	}
+	return leftover
+}
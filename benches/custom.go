@@ -2,13 +2,21 @@ package benches
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/estesp/bucketbench/driver"
+	"github.com/estesp/bucketbench/stats"
+	"github.com/estesp/bucketbench/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -16,49 +24,445 @@ import (
 // defined in the provided YAML against specified image and driver types
 type CustomBench struct {
 	driver.Config
-	benchName   string
-	driver      driver.Driver
-	imageInfo   string
-	cmdOverride string
-	trace       bool
-	stats       []RunStatistics
-	elapsed     time.Duration
-	state       State
+	benchName       string
+	driver          driver.Driver
+	imageInfo       string
+	cmdOverride     []string
+	trace           bool
+	stats           []RunStatistics
+	elapsed         time.Duration
+	state           State
+	cleanup         *cleanupRegistry
+	customMetrics   []customMetricPattern
+	commandTimeout  time.Duration
+	commandDelay    time.Duration
+	retryAttempts   int
+	retryBackoff    time.Duration
+	iterationDelay  time.Duration
+	delayJitter     time.Duration
+	rampUp          time.Duration
+	runDuration     time.Duration
+	targetRate      float64
+	tokens          chan struct{}
+	maxInflight     int
+	inflight        chan struct{}
+	execCommand     string
+	clientPerThread bool
+	ctrMetricsOn    bool
+	firstProcOn     bool
+	traceSyscalls   bool
+	profile         *ProfileHook
+	coldDaemon      bool
+	daemonUnit      string
+	boundedStats    bool
+	rawStatsFile    string
+	chaos           *ChaosConfig
+	images          []string
+	imageRandom     bool
+	summary         *RunSummary
+	ctrMetricsMu    sync.Mutex
+	ctrMetrics      containerMetricsAgg
+
+	readyCheck      *ReadyCheck
+	readyLogPattern *regexp.Regexp
+	expect          *Expectation
+	expectPattern   *regexp.Regexp
 }
 
-// Init initializes the benchmark
-func (cb *CustomBench) Init(ctx context.Context, name string, driverType driver.Type, binaryPath, imageInfo, cmdOverride string, trace bool) error {
-	cb.DriverType = driverType
-	cb.Path = binaryPath
+const (
+	defaultReadyTimeout = 60 * time.Second
+	readyPollInterval   = 100 * time.Millisecond
+	bytesInMiB          = 1024 * 1024
+)
+
+// waitForReady polls the configured readiness check (probe exec or log
+// pattern) until it succeeds or the check's timeout elapses
+func (cb *CustomBench) waitForReady(ctx context.Context, runner driver.Driver, ctr driver.Container) error {
+	timeout := defaultReadyTimeout
+	if cb.readyCheck.TimeoutSec > 0 {
+		timeout = time.Duration(cb.readyCheck.TimeoutSec) * time.Second
+	}
+	deadline := time.Now().Add(timeout)
+
+	for time.Now().Before(deadline) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		switch {
+		case cb.readyCheck.Probe != "":
+			if _, _, err := runner.Exec(ctx, ctr, cb.readyCheck.Probe); err == nil {
+				return nil
+			}
+		case cb.readyLogPattern != nil:
+			reader, err := runner.Logs(ctx, ctr)
+			if err == nil {
+				data, _ := io.ReadAll(reader)
+				reader.Close()
+				if cb.readyLogPattern.Match(data) {
+					return nil
+				}
+			}
+		default:
+			// no probe or pattern defined; treat as immediately ready
+			return nil
+		}
+		time.Sleep(readyPollInterval)
+	}
+	return fmt.Errorf("container %q not ready within %v", ctr.Name(), timeout)
+}
+
+// defaultExecCommand is run inside containers by the 'exec' benchmark
+// command when the YAML does not specify one
+const defaultExecCommand = "echo bucketbench"
+
+// rotation orders accepted for a multi-image benchmark
+const (
+	imageOrderRoundRobin = "round-robin"
+	imageOrderRandom     = "random"
+)
+
+// iterationImage returns the image the given thread uses for the given
+// iteration: the single benchmark image normally, or the next entry of the
+// configured rotation when an image list is set. Round-robin is offset by
+// thread number so concurrent threads spread across the list instead of
+// hitting the same image in lockstep.
+func (cb *CustomBench) iterationImage(thread, iteration int) string {
+	if len(cb.images) == 0 {
+		return cb.imageInfo
+	}
+	if cb.imageRandom {
+		return cb.images[rand.Intn(len(cb.images))]
+	}
+	return cb.images[(thread+iteration)%len(cb.images)]
+}
+
+// delay sleeps for the given base duration plus a random jitter (when
+// configured); used for think-time between commands and iterations
+func (cb *CustomBench) delay(base time.Duration) {
+	d := base
+	if cb.delayJitter > 0 {
+		d += time.Duration(rand.Int63n(int64(cb.delayJitter)))
+	}
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// execWithRetry invokes a driver operation, retrying failures up to the
+// configured number of extra attempts with a linearly increasing backoff;
+// attempts beyond the first are counted in the iteration's retry map so
+// noisy-environment retries don't show up as errors
+func (cb *CustomBench) execWithRetry(key string, retries map[string]int, op func() (string, time.Duration, error)) (string, time.Duration, error) {
+	out, elapsed, err := op()
+	for attempt := 1; err != nil && attempt <= cb.retryAttempts; attempt++ {
+		time.Sleep(cb.retryBackoff * time.Duration(attempt))
+		retries[key]++
+		out, elapsed, err = op()
+	}
+	return out, elapsed, err
+}
 
-	driver, err := driver.New(ctx, &cb.Config)
+// containerMetricsAgg accumulates per-container resource samples across all
+// iterations and threads of a run
+type containerMetricsAgg struct {
+	samples  int
+	memSum   uint64
+	memMax   uint64
+	cpuTotal time.Duration
+}
+
+// collectContainerMetrics samples one container's memory and CPU usage at
+// the stats interval until the iteration's stats context is canceled, then
+// folds the results into the run-wide aggregate
+func (cb *CustomBench) collectContainerMetrics(ctx context.Context, sampler driver.ContainerMetricser, ctr driver.Container) {
+	var (
+		samples           int
+		memSum, memMax    uint64
+		firstCPU, lastCPU uint64
+		haveCPU           bool
+	)
+	ticker := time.NewTicker(cb.StatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if samples > 0 {
+				cb.ctrMetricsMu.Lock()
+				cb.ctrMetrics.samples += samples
+				cb.ctrMetrics.memSum += memSum
+				if memMax > cb.ctrMetrics.memMax {
+					cb.ctrMetrics.memMax = memMax
+				}
+				cb.ctrMetrics.cpuTotal += time.Duration(lastCPU - firstCPU)
+				cb.ctrMetricsMu.Unlock()
+			}
+			return
+		case <-ticker.C:
+			mem, cpu, err := sampler.ContainerMetrics(ctx, ctr)
+			if err != nil {
+				// the container has likely exited or been removed
+				continue
+			}
+			samples++
+			memSum += mem
+			if mem > memMax {
+				memMax = mem
+			}
+			if !haveCPU {
+				firstCPU = cpu
+				haveCPU = true
+			}
+			lastCPU = cpu
+		}
+	}
+}
+
+// coldRestartDaemon restarts the engine's systemd unit and waits for the
+// daemon to answer an info query again, so the measured run starts against
+// a freshly started daemon with no warmed caches from previous runs
+func (cb *CustomBench) coldRestartDaemon(ctx context.Context) error {
+	controller, err := utils.NewSystemdController(ctx, cb.daemonUnit)
 	if err != nil {
-		return fmt.Errorf("error during driver initialization for CustomBench: %v", err)
+		return err
 	}
+	defer controller.Close()
 
-	if driver == nil {
-		return fmt.Errorf("driver initialization failed for type %v", driverType.String())
+	log.Infof("cold-daemon mode: restarting unit %q before run", cb.daemonUnit)
+	if _, err := controller.Restart(ctx); err != nil {
+		return err
 	}
 
-	// get driver info; will also validate for daemon-based variants whether system is ready/up
-	// and running for benchmarking
-	info, err := driver.Info(ctx)
+	deadline := time.Now().Add(defaultReadyTimeout)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := cb.driver.Info(ctx); err == nil {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			return fmt.Errorf("daemon not reachable within %v of cold restart", defaultReadyTimeout)
+		}
+		time.Sleep(readyPollInterval)
+	}
+}
+
+// maybeProfile attaches the configured perf/strace hook to the daemon when
+// this command is the hooked one and the iteration falls in the sampled
+// subset; returns nil when no profiling applies to this invocation
+func (cb *CustomBench) maybeProfile(cmd string, runner driver.Driver, threadNum, iteration int) *profiler {
+	if cb.profile == nil || !strings.EqualFold(cmd, cb.profile.Command) {
+		return nil
+	}
+	every := cb.profile.SampleEvery
+	if every < 1 {
+		every = 1
+	}
+	if iteration%every != 0 {
+		return nil
+	}
+	pid, err := runner.PID()
 	if err != nil {
-		return fmt.Errorf("error during driver info query: %v", err)
+		log.Warnf("profile hook: could not determine daemon pid: %v", err)
+		return nil
+	}
+	label := fmt.Sprintf("%s-t%d-i%d", strings.ToLower(cmd), threadNum, iteration)
+	prof, err := startProfiler(cb.profile, pid, label)
+	if err != nil {
+		log.Warnf("profile hook: %v", err)
+		return nil
+	}
+	return prof
+}
+
+// error classes recorded as "<command>:<class>" entries alongside the plain
+// per-command error count, so the detailed output can break down what kind
+// of failures occurred rather than just how many
+const (
+	errClassTimeout     = "timeout"
+	errClassNotFound    = "not-found"
+	errClassConflict    = "conflict"
+	errClassUnreachable = "daemon-unreachable"
+	errClassNonZeroExit = "non-zero-exit"
+)
+
+// classifyError buckets a driver operation error into one of the known
+// error classes based on common engine/CLI error text, or "" when the
+// failure doesn't match any known class
+func classifyError(err error) string {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "deadline exceeded") || strings.Contains(msg, "timed out") || strings.Contains(msg, "timeout"):
+		return errClassTimeout
+	case strings.Contains(msg, "no such") || strings.Contains(msg, "not found"):
+		return errClassNotFound
+	case strings.Contains(msg, "already exists") || strings.Contains(msg, "already in progress") || strings.Contains(msg, "conflict") || strings.Contains(msg, "in use"):
+		return errClassConflict
+	case strings.Contains(msg, "connection refused") || strings.Contains(msg, "cannot connect") || strings.Contains(msg, "connection reset") || strings.Contains(msg, "daemon") || strings.Contains(msg, "transport is closing") || strings.Contains(msg, "dial unix"):
+		return errClassUnreachable
+	case strings.Contains(msg, "exit status") || strings.Contains(msg, "exit code"):
+		return errClassNonZeroExit
+	default:
+		return ""
 	}
+}
+
+// recordError counts a failed operation under its per-command key and,
+// when the error matches a known class, under the suffixed class key too
+func recordError(errs map[string]int, key string, err error) {
+	errs[key]++
+	if class := classifyError(err); class != "" {
+		errs[key+":"+class]++
+	}
+}
 
-	log.Infof("driver initialized: %s", info)
+// checkOutputExpect validates container run output against the configured
+// stdout assertions, returning a descriptive error on the first mismatch
+func (cb *CustomBench) checkOutputExpect(out string) error {
+	if cb.expect.StdoutContains != "" && !strings.Contains(out, cb.expect.StdoutContains) {
+		return fmt.Errorf("output does not contain %q", cb.expect.StdoutContains)
+	}
+	if cb.expectPattern != nil && !cb.expectPattern.MatchString(out) {
+		return fmt.Errorf("output does not match pattern %q", cb.expectPattern.String())
+	}
+	return nil
+}
 
-	// prepare environment
-	err = driver.Clean(ctx)
+// checkExitCodeExpect validates the exit code reported by 'wait' against the
+// expected value; drivers which don't report an exit code are skipped
+func (cb *CustomBench) checkExitCodeExpect(out string) error {
+	trimmed := strings.TrimSpace(out)
+	if trimmed == "" {
+		return nil
+	}
+	code, err := strconv.Atoi(trimmed)
 	if err != nil {
-		return fmt.Errorf("error during driver init cleanup: %v", err)
+		return nil
+	}
+	if code != *cb.expect.ExitCode {
+		return fmt.Errorf("exit code %d, expected %d", code, *cb.expect.ExitCode)
+	}
+	return nil
+}
+
+// statKey maps a YAML command (including its aliases) to the key used for
+// its duration and error entries in RunStatistics
+func statKey(cmd string) string {
+	switch strings.ToLower(cmd) {
+	case "run", "start":
+		return "run"
+	case "stop", "kill":
+		return "stop"
+	case "remove", "erase", "delete":
+		return "delete"
+	case "unpause", "resume":
+		return "resume"
+	case "metrics", "stats":
+		return "metrics"
+	case "createvolume":
+		return "createVolume"
+	case "removevolume":
+		return "removeVolume"
+	default:
+		return strings.ToLower(cmd)
+	}
+}
+
+// customMetricPattern is the compiled form of a YAML-declared custom metric
+type customMetricPattern struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// compileCustomMetrics compiles the regex patterns of YAML-declared custom
+// metrics, erroring out early on any invalid pattern
+func compileCustomMetrics(metrics []CustomMetric) ([]customMetricPattern, error) {
+	var compiled []customMetricPattern
+	for _, metric := range metrics {
+		if metric.Name == "" {
+			return nil, fmt.Errorf("custom metric with pattern %q requires a name", metric.Pattern)
+		}
+		re, err := regexp.Compile(metric.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for custom metric %q: %v", metric.Name, err)
+		}
+		compiled = append(compiled, customMetricPattern{name: metric.Name, pattern: re})
+	}
+	return compiled, nil
+}
+
+// extractCustomMetrics matches container output against the declared custom
+// metric patterns, merging any extracted millisecond values into the current
+// iteration's durations map
+func (cb *CustomBench) extractCustomMetrics(output string, durations map[string]time.Duration) {
+	for _, metric := range cb.customMetrics {
+		match := metric.pattern.FindStringSubmatch(output)
+		if match == nil {
+			continue
+		}
+		// use the first capture group if one exists; otherwise the full match
+		value := match[0]
+		if len(match) > 1 {
+			value = match[1]
+		}
+		ms, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			log.Warnf("custom metric %q: could not parse %q as a number: %v", metric.name, value, err)
+			continue
+		}
+		durations[metric.name] = time.Duration(ms * float64(time.Millisecond))
+	}
+}
+
+// Driver returns the initialized driver so callers running a thread sweep
+// can hand it to the next benchmark instance instead of re-dialing
+func (cb *CustomBench) Driver() driver.Driver {
+	return cb.driver
+}
+
+// ReuseDriver seeds the benchmark with an already-initialized driver;
+// Init then skips driver creation, the info query, and the initial
+// environment cleanup (the prior run's final cleanup already ran)
+func (cb *CustomBench) ReuseDriver(drv driver.Driver) {
+	cb.driver = drv
+}
+
+// Init initializes the benchmark
+func (cb *CustomBench) Init(ctx context.Context, name string, driverType driver.Type, binaryPath, imageInfo string, cmdOverride []string, trace bool) error {
+	cb.DriverType = driverType
+	cb.Path = binaryPath
+
+	if cb.driver == nil {
+		drv, err := driver.New(ctx, &cb.Config)
+		if err != nil {
+			return fmt.Errorf("error during driver initialization for CustomBench: %v", err)
+		}
+
+		if drv == nil {
+			return fmt.Errorf("driver initialization failed for type %v", driverType.String())
+		}
+
+		// get driver info; will also validate for daemon-based variants whether system is ready/up
+		// and running for benchmarking
+		info, err := drv.Info(ctx)
+		if err != nil {
+			return fmt.Errorf("error during driver info query: %v", err)
+		}
+
+		log.Infof("driver initialized: %s", info)
+
+		// prepare environment
+		if err := drv.Clean(ctx); err != nil {
+			return fmt.Errorf("error during driver init cleanup: %v", err)
+		}
+
+		cb.driver = drv
 	}
 
 	cb.benchName = name
 	cb.imageInfo = imageInfo
 	cb.cmdOverride = cmdOverride
-	cb.driver = driver
 	cb.trace = trace
 	return nil
 }
@@ -80,8 +484,13 @@ func (cb *CustomBench) Validate(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("Driver validation: error stopping test container: %v", err)
 	}
-	// allow time for quiesce of stopped state in process and container executor metadata
-	time.Sleep(50 * time.Millisecond)
+	// allow time for quiesce of stopped state in process and container executor
+	// metadata; use the configured inter-command delay when one is set
+	if cb.commandDelay > 0 {
+		cb.delay(cb.commandDelay)
+	} else {
+		time.Sleep(50 * time.Millisecond)
+	}
 
 	_, _, err = cb.driver.Remove(ctx, ctr)
 	if err != nil {
@@ -90,10 +499,72 @@ func (cb *CustomBench) Validate(ctx context.Context) error {
 	return nil
 }
 
+// acquireOp blocks until a global in-flight slot is free when a
+// concurrency cap is configured; the returned func releases the slot.
+// Without a cap (or once the run is canceled) it is a no-op.
+func (cb *CustomBench) acquireOp(ctx context.Context) func() {
+	if cb.inflight == nil {
+		return func() {}
+	}
+	select {
+	case cb.inflight <- struct{}{}:
+		return func() { <-cb.inflight }
+	case <-ctx.Done():
+		return func() {}
+	}
+}
+
+// supportedCommands filters the benchmark command list against the
+// driver's declared capabilities, warning for each dropped command rather
+// than recording zero-duration no-ops or guaranteed errors for it
+func (cb *CustomBench) supportedCommands(commands []string) []string {
+	caps := cb.driver.Capabilities()
+	supported := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		ok := true
+		switch strings.ToLower(cmd) {
+		case "pause", "unpause", "resume":
+			ok = caps.Pause
+		case "exec":
+			ok = caps.Exec
+		case "wait":
+			ok = caps.Wait
+		case "logs":
+			ok = caps.Logs
+		case "metrics", "stats":
+			ok = caps.Metrics
+		case "checkpoint", "restore":
+			ok = caps.Checkpoint
+		}
+		if !ok {
+			log.Warnf("driver %s does not support command %q; skipping", cb.driver.Type(), cmd)
+			continue
+		}
+		supported = append(supported, cmd)
+	}
+	return supported
+}
+
 // Run executes the benchmark iterations against a specific engine driver type
 // for a specified number of iterations
 func (cb *CustomBench) Run(ctx context.Context, threads, iterations int, commands []string) error {
 	log.Infof("Start CustomBench run: threads (%d); iterations (%d)", threads, iterations)
+	commands = cb.supportedCommands(commands)
+	// run-scoped registry of created resources; swept before the final
+	// driver Clean so thread panics or mid-run errors can't leak containers
+	cb.cleanup = newCleanupRegistry()
+
+	// with a global concurrency cap configured, a semaphore bounds driver
+	// operations in flight across all threads (distinct from thread count)
+	if cb.maxInflight > 0 {
+		log.Infof("limiting driver operations to %d in flight across %d threads", cb.maxInflight, threads)
+		cb.inflight = make(chan struct{}, cb.maxInflight)
+	}
+	if cb.coldDaemon {
+		if err := cb.coldRestartDaemon(ctx); err != nil {
+			return fmt.Errorf("cold-daemon restart failed: %v", err)
+		}
+	}
 	statChan := make([]chan RunStatistics, threads)
 	for i := range statChan {
 		statChan[i] = make(chan RunStatistics, iterations)
@@ -101,116 +572,499 @@ func (cb *CustomBench) Run(ctx context.Context, threads, iterations int, command
 	cb.state = Running
 	start := time.Now()
 
-	var wg sync.WaitGroup
-	for i := 0; i < threads; i++ {
-		// create a driver instance for each thread to protect from drivers
-		// which may not be threadsafe (e.g. gRPC client connection in containerd?)
-		drv, err := driver.New(ctx, &cb.Config)
+	// with syscall tracing requested, count clone/execve and selected
+	// syscalls from the daemon and shim processes for the whole run
+	var syscallCollector *stats.SyscallCollector
+	if cb.traceSyscalls {
+		collector, err := stats.NewSyscallCollector(cb.driver.ProcNames())
 		if err != nil {
-			return fmt.Errorf("error creating new driver for thread %d: %v", i, err)
+			log.Warnf("syscall tracing unavailable: %v", err)
+		} else if err := collector.Start(); err != nil {
+			log.Warnf("failed to start syscall tracing: %v", err)
+		} else {
+			syscallCollector = collector
 		}
+	}
+
+	// with chaos configured, inject faults on a random schedule for the
+	// duration of the run; recovery times join the stats afterwards
+	var chaosInj *chaosInjector
+	if cb.chaos != nil {
+		chaosInj = newChaosInjector(cb.chaos, cb.driver, cb.daemonUnit)
+		chaosInj.start(ctx)
+	}
+
+	// with a target rate configured, pace iterations open-loop from a token
+	// bucket instead of running them back-to-back; the bucket holds at most
+	// one token per thread so an idle period can't produce a large burst
+	if cb.targetRate > 0 {
+		cb.tokens = make(chan struct{}, threads)
+		tokenInterval := time.Duration(float64(time.Second) / cb.targetRate)
+		log.Infof("pacing iterations at %.2f ops/sec (one token every %v)", cb.targetRate, tokenInterval)
+		ticker := time.NewTicker(tokenInterval)
+		defer ticker.Stop()
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					select {
+					case cb.tokens <- struct{}{}:
+					default:
+						// bucket full; drop the token
+					}
+				}
+			}
+		}()
+	}
+
+	// when a ramp-up period is configured, stagger worker thread starts
+	// evenly across it so daemon behavior can be observed as concurrency grows
+	var stagger time.Duration
+	if cb.rampUp > 0 && threads > 1 {
+		stagger = cb.rampUp / time.Duration(threads-1)
+		log.Infof("ramping up %d threads over %v (one thread every %v)", threads, cb.rampUp, stagger)
+	}
+
+	// with bounded-memory aggregation enabled, iteration entries fold into
+	// online per-command summaries instead of accumulating in slices; an
+	// optional raw dump streams the entries to disk for offline analysis.
+	// The dump file is opened before any worker launches so a failure here
+	// aborts the run with nothing in flight
+	var (
+		agg    *statsAggregator
+		aggMu  sync.Mutex
+		rawEnc *json.Encoder
+	)
+	if cb.boundedStats {
+		agg = newStatsAggregator()
+		if cb.rawStatsFile != "" {
+			rawFile, err := os.Create(cb.rawStatsFile)
+			if err != nil {
+				return fmt.Errorf("can't create raw stats dump file %q: %v", cb.rawStatsFile, err)
+			}
+			defer rawFile.Close()
+			rawEnc = json.NewEncoder(rawFile)
+		}
+	}
+
+	// worker threads share the driver created during Init; drivers are
+	// safe for concurrent use, so one client connection serves all
+	// threads the way a real deployment's client would. With
+	// clientPerThread set, each worker instead dials its own client so
+	// connection multiplexing effects can be measured; those clients are
+	// all created before any worker launches so a dial failure can abort
+	// the run with nothing in flight
+	runners := make([]driver.Driver, threads)
+	for i := range runners {
+		runners[i] = cb.driver
+		if cb.clientPerThread {
+			drv, err := driver.New(ctx, &cb.Config)
+			if err != nil {
+				for _, created := range runners[:i] {
+					if closeErr := created.Close(); closeErr != nil {
+						log.Errorf("error on closing driver: %v", closeErr)
+					}
+				}
+				return fmt.Errorf("error creating per-thread driver for thread %d: %v", i, err)
+			}
+			runners[i] = drv
+		}
+	}
 
+	var wg sync.WaitGroup
+	for i := 0; i < threads; i++ {
 		wg.Add(1)
-		go func(index int) {
+		go func(index int, runner driver.Driver, ownClient bool) {
 			defer wg.Done()
-			cb.runThread(ctx, drv, index, iterations, commands, statChan[index])
-		}(i)
+			if ownClient {
+				defer func() {
+					if err := runner.Close(); err != nil {
+						log.Errorf("error on closing driver: %v", err)
+					}
+				}()
+			}
+			if stagger > 0 {
+				time.Sleep(time.Duration(index) * stagger)
+			}
+			cb.runThread(ctx, runner, index, iterations, commands, statChan[index])
+		}(i, runners[i], cb.clientPerThread)
 	}
+
+	// collect stats concurrently with the run; duration-based runs can
+	// produce more entries than the per-channel buffer holds
+	threadStats := make([][]RunStatistics, threads)
+	var collectWg sync.WaitGroup
+	for i, ch := range statChan {
+		collectWg.Add(1)
+		go func(index int, ch chan RunStatistics) {
+			defer collectWg.Done()
+			for statEntry := range ch {
+				// daemon overhead samples stay raw even when aggregating;
+				// their volume is bounded by the sampling interval
+				if agg == nil || statEntry.Daemon != nil {
+					threadStats[index] = append(threadStats[index], statEntry)
+					continue
+				}
+				aggMu.Lock()
+				agg.add(statEntry)
+				if rawEnc != nil {
+					if err := rawEnc.Encode(statEntry); err != nil {
+						log.Warnf("error writing raw stats dump: %v", err)
+						rawEnc = nil
+					}
+				}
+				aggMu.Unlock()
+			}
+		}(i, ch)
+	}
+
 	wg.Wait()
 	cb.elapsed = time.Since(start)
 
 	log.Infof("CustomBench threads complete in %v time elapsed", cb.elapsed)
-	//collect stats
-	for _, ch := range statChan {
-		for statEntry := range ch {
-			cb.stats = append(cb.stats, statEntry)
-		}
+	collectWg.Wait()
+	for _, entries := range threadStats {
+		cb.stats = append(cb.stats, entries...)
+	}
+	if chaosInj != nil {
+		cb.stats = append(cb.stats, chaosInj.stop()...)
+	}
+	if agg != nil {
+		cb.summary = agg.summary()
 	}
 	cb.state = Completed
+
+	if syscallCollector != nil {
+		counts, err := syscallCollector.Stop()
+		if err != nil {
+			log.Warnf("error collecting syscall counts: %v", err)
+		} else {
+			names := make([]string, 0, len(counts))
+			for name := range counts {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			var parts []string
+			for _, name := range names {
+				parts = append(parts, fmt.Sprintf("%s=%d", name, counts[name]))
+			}
+			log.Infof("syscall counts (%s): %s", strings.Join(cb.driver.ProcNames(), ","), strings.Join(parts, " "))
+		}
+	}
+
+	if cb.ctrMetricsOn && cb.ctrMetrics.samples > 0 {
+		avgMem := cb.ctrMetrics.memSum / uint64(cb.ctrMetrics.samples) / bytesInMiB
+		log.Infof("container metrics: %d samples, avg mem %d MiB, max mem %d MiB, total container CPU %v",
+			cb.ctrMetrics.samples, avgMem, cb.ctrMetrics.memMax/bytesInMiB, cb.ctrMetrics.cpuTotal)
+	}
+
+	// sweep whatever the threads created but did not remove, using a
+	// context independent of the run's so cleanup still proceeds after a
+	// signal canceled it; anything unremovable is reported rather than
+	// silently leaked
+	if leftover := cb.cleanup.sweep(context.Background()); len(leftover) > 0 {
+		sort.Strings(leftover)
+		log.Warnf("cleanup: %d resource(s) could not be removed: %s", len(leftover), strings.Join(leftover, ", "))
+	}
+
 	// final environment cleanup
 	if err := cb.driver.Clean(ctx); err != nil {
 		return fmt.Errorf("Error during driver final cleanup: %v", err)
 	}
+
+	// re-query for anything cleanup missed so engines which fail to tear
+	// down under churn are called out rather than leaking silently
+	auditLeaks(ctx, cb.driver)
 	return nil
 }
 
 func (cb *CustomBench) runThread(ctx context.Context, runner driver.Driver, threadNum, iterations int, commands []string, stats chan RunStatistics) {
+	defer close(stats)
+	// a panicking thread must not take the process (and every other
+	// thread's cleanup) down with it; its containers stay in the registry
+	// and are removed by the end-of-run sweep
 	defer func() {
-		if err := runner.Close(); err != nil {
-			log.Errorf("error on closing driver: %v", err)
+		if r := recover(); r != nil {
+			log.Errorf("benchmark thread %d panicked: %v; its containers will be removed by the cleanup sweep", threadNum, r)
 		}
-		close(stats)
 	}()
 
-	for i := 0; i < iterations; i++ {
+	// duration-based runs loop the command list until the deadline rather
+	// than for a fixed iteration count
+	var deadline time.Time
+	if cb.runDuration > 0 {
+		deadline = time.Now().Add(cb.runDuration)
+	}
+
+	for i := 0; cb.runDuration > 0 || i < iterations; i++ {
+		if cb.runDuration > 0 && !time.Now().Before(deadline) {
+			break
+		}
+		if cb.tokens != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-cb.tokens:
+			}
+		}
 		errors := make(map[string]int)
 		durations := make(map[string]time.Duration)
+		retries := make(map[string]int)
 		// commands are specified in the passed in array; we will need
 		// a container for each set of commands:
 		name := fmt.Sprintf("%s-%d-%d", driver.ContainerNamePrefix, threadNum, i)
-		ctr, err := runner.Create(ctx, name, cb.imageInfo, cb.cmdOverride, true, cb.trace)
+		image := cb.iterationImage(threadNum, i)
+		releaseCreate := cb.acquireOp(ctx)
+		createStart := time.Now()
+		ctr, err := runner.Create(ctx, name, image, cb.cmdOverride, true, cb.trace)
+		releaseCreate()
 		if err != nil {
-			log.Errorf("Error on creating container %q from image %q: %v", name, cb.imageInfo, err)
+			log.Errorf("Error on creating container %q from image %q: %v", name, image, err)
 			return
 		}
+		createElapsed := time.Since(createStart)
+		cb.cleanup.track(runner, ctr)
 
 		// Stats calls must be stopped at the end of current iteration if streaming
 		statsCtx, statsCancel := context.WithCancel(ctx)
 
 		for _, cmd := range commands {
 			log.Debugf("running command: %s", cmd)
+			// bound each driver operation with a deadline when a command
+			// timeout is configured so a hung engine call can't stall the
+			// thread for the rest of the run
+			cmdCtx := ctx
+			var cmdCancel context.CancelFunc
+			if cb.commandTimeout > 0 {
+				cmdCtx, cmdCancel = context.WithTimeout(ctx, cb.commandTimeout)
+			}
+			prof := cb.maybeProfile(cmd, runner, threadNum, i)
+			releaseOp := cb.acquireOp(cmdCtx)
 			switch strings.ToLower(cmd) {
+			case "create":
+				// the container is created ahead of the command loop so
+				// later commands have an instance to act on; listing
+				// "create" surfaces that measured latency as its own stat
+				durations["create"] = createElapsed
 			case "run", "start":
-				out, runElapsed, err := runner.Run(ctx, ctr)
+				// watching starts before the run call so a short-lived init
+				// process can't appear and exit unobserved
+				var firstProcC chan time.Duration
+				if cb.firstProcOn {
+					firstProcC = make(chan time.Duration, 1)
+					procStart := time.Now()
+					go func() {
+						if werr := utils.WaitForFirstProcess(cmdCtx, name); werr != nil {
+							close(firstProcC)
+							return
+						}
+						firstProcC <- time.Since(procStart)
+					}()
+				}
+				out, runElapsed, err := cb.execWithRetry("run", retries, func() (string, time.Duration, error) {
+					return runner.Run(cmdCtx, ctr)
+				})
 				if err != nil {
-					errors["run"]++
+					recordError(errors, "run", err)
 					log.Warnf("Error during container command %q on %q: %v\n  Output: %s", cmd, name, err, out)
+				} else if cb.readyCheck != nil {
+					// extend the run timing until the container reports ready
+					readyStart := time.Now()
+					if rerr := cb.waitForReady(cmdCtx, runner, ctr); rerr != nil {
+						recordError(errors, "run", rerr)
+						log.Warnf("Readiness check failed for container %q: %v", name, rerr)
+					} else {
+						runElapsed += time.Since(readyStart)
+					}
+				}
+				if err == nil && cb.ctrMetricsOn {
+					if sampler, ok := runner.(driver.ContainerMetricser); ok {
+						go cb.collectContainerMetrics(statsCtx, sampler, ctr)
+					} else {
+						log.Debugf("driver %s does not support per-container metrics", runner.Type())
+					}
+				}
+				if err == nil && cb.expect != nil {
+					if aerr := cb.checkOutputExpect(out); aerr != nil {
+						errors["run"]++
+						errors["run:assertion"]++
+						log.Warnf("Expectation failed for container %q: %v", name, aerr)
+					}
 				}
 				durations["run"] = runElapsed
+				if err == nil && firstProcC != nil {
+					// bounded by the command context the watcher runs under
+					if ttfp, ok := <-firstProcC; ok {
+						durations["firstProcess"] = ttfp
+					} else {
+						log.Warnf("no first process observed for container %q before timeout", name)
+					}
+				}
+				if len(cb.customMetrics) > 0 {
+					cb.extractCustomMetrics(out, durations)
+				}
 				log.Debug(out)
 			case "stop", "kill":
-				out, stopElapsed, err := runner.Stop(ctx, ctr)
+				out, stopElapsed, err := cb.execWithRetry("stop", retries, func() (string, time.Duration, error) {
+					return runner.Stop(cmdCtx, ctr)
+				})
 				if err != nil {
-					errors["stop"]++
+					recordError(errors, "stop", err)
 					log.Warnf("Error during container command %q on %q: %v\n  Output: %s", cmd, name, err, out)
 				}
 				durations["stop"] = stopElapsed
 				log.Debug(out)
 			case "remove", "erase", "delete":
-				out, rmElapsed, err := runner.Remove(ctx, ctr)
+				out, rmElapsed, err := cb.execWithRetry("delete", retries, func() (string, time.Duration, error) {
+					return runner.Remove(cmdCtx, ctr)
+				})
 				if err != nil {
-					errors["delete"]++
+					recordError(errors, "delete", err)
 					log.Warnf("Error during container command %q on %q: %v\n  Output: %s", cmd, name, err, out)
+				} else {
+					cb.cleanup.release(name)
 				}
 				durations["delete"] = rmElapsed
 				log.Debug(out)
 			case "pause":
-				out, pauseElapsed, err := runner.Pause(ctx, ctr)
+				out, pauseElapsed, err := cb.execWithRetry("pause", retries, func() (string, time.Duration, error) {
+					return runner.Pause(cmdCtx, ctr)
+				})
 				if err != nil {
-					errors["pause"]++
+					recordError(errors, "pause", err)
 					log.Warnf("Error during container command %q on %q: %v\n  Output: %s", cmd, name, err, out)
 				}
 				durations["pause"] = pauseElapsed
 				log.Debug(out)
 			case "unpause", "resume":
-				out, unpauseElapsed, err := runner.Unpause(ctx, ctr)
+				out, unpauseElapsed, err := cb.execWithRetry("resume", retries, func() (string, time.Duration, error) {
+					return runner.Unpause(cmdCtx, ctr)
+				})
 				if err != nil {
-					errors["resume"]++
+					recordError(errors, "resume", err)
 					log.Warnf("Error during container command %q on %q: %v\n  Output: %s", cmd, name, err, out)
 				}
 				durations["resume"] = unpauseElapsed
 				log.Debug(out)
+			case "exec":
+				execCmd := cb.execCommand
+				if execCmd == "" {
+					execCmd = defaultExecCommand
+				}
+				out, execElapsed, err := cb.execWithRetry("exec", retries, func() (string, time.Duration, error) {
+					return runner.Exec(cmdCtx, ctr, execCmd)
+				})
+				if err != nil {
+					recordError(errors, "exec", err)
+					log.Warnf("Error during container command %q on %q: %v\n  Output: %s", cmd, name, err, out)
+				}
+				durations["exec"] = execElapsed
+				log.Debug(out)
+			case "checkpoint":
+				out, ckptElapsed, err := runner.Checkpoint(cmdCtx, ctr)
+				if err != nil {
+					recordError(errors, "checkpoint", err)
+					log.Warnf("Error during container command %q on %q: %v\n  Output: %s", cmd, name, err, out)
+				}
+				durations["checkpoint"] = ckptElapsed
+				log.Debug(out)
+			case "restore":
+				out, restoreElapsed, err := runner.Restore(cmdCtx, ctr)
+				if err != nil {
+					recordError(errors, "restore", err)
+					log.Warnf("Error during container command %q on %q: %v\n  Output: %s", cmd, name, err, out)
+				}
+				durations["restore"] = restoreElapsed
+				log.Debug(out)
+			case "createvolume", "removevolume":
+				op := "createVolume"
+				if strings.ToLower(cmd) == "removevolume" {
+					op = "removeVolume"
+				}
+				volumes, ok := runner.(driver.VolumeManager)
+				if !ok {
+					errors[op]++
+					log.Warnf("Command %q requires volume support which driver %s does not provide", cmd, runner.Type())
+					break
+				}
+				volumeName := "bb-vol-" + name
+				var (
+					out        string
+					volElapsed time.Duration
+					err        error
+				)
+				if op == "createVolume" {
+					out, volElapsed, err = volumes.CreateVolume(cmdCtx, volumeName)
+				} else {
+					out, volElapsed, err = volumes.RemoveVolume(cmdCtx, volumeName)
+				}
+				if err != nil {
+					recordError(errors, op, err)
+					log.Warnf("Error during container command %q on %q: %v\n  Output: %s", cmd, name, err, out)
+				}
+				durations[op] = volElapsed
+				log.Debug(out)
+			case "snapshot", "commit", "diff":
+				op := strings.ToLower(cmd)
+				snapshotter, ok := runner.(driver.SnapshotBenchmarker)
+				if !ok {
+					errors[op]++
+					log.Warnf("Command %q requires snapshotter support which driver %s does not provide", cmd, runner.Type())
+					break
+				}
+				var (
+					out         string
+					snapElapsed time.Duration
+					err         error
+				)
+				switch op {
+				case "snapshot":
+					out, snapElapsed, err = snapshotter.SnapshotPrepare(cmdCtx, ctr)
+				case "commit":
+					out, snapElapsed, err = snapshotter.SnapshotCommit(cmdCtx, ctr)
+				case "diff":
+					out, snapElapsed, err = snapshotter.SnapshotDiff(cmdCtx, ctr)
+				}
+				if err != nil {
+					recordError(errors, op, err)
+					log.Warnf("Error during container command %q on %q: %v\n  Output: %s", cmd, name, err, out)
+				}
+				durations[op] = snapElapsed
+				log.Debug(out)
 			case "wait":
-				out, waitElapsed, err := runner.Wait(ctx, ctr)
+				out, waitElapsed, err := runner.Wait(cmdCtx, ctr)
 				if err != nil {
-					errors["wait"]++
+					recordError(errors, "wait", err)
 					log.Warnf("Error during container command %q on %q: %v\n  Output: %s", cmd, name, err, out)
+				} else if cb.expect != nil && cb.expect.ExitCode != nil {
+					if aerr := cb.checkExitCodeExpect(out); aerr != nil {
+						errors["wait"]++
+						errors["wait:assertion"]++
+						log.Warnf("Expectation failed for container %q: %v", name, aerr)
+					}
 				}
 				durations["wait"] = waitElapsed
 				log.Debug(out)
+			case "logs":
+				logStart := time.Now()
+				reader, err := runner.Logs(cmdCtx, ctr)
+				if err != nil {
+					recordError(errors, "logs", err)
+					log.Warnf("Error during container command %q on %q: %v", cmd, name, err)
+					break
+				}
+				// the measured operation is retrieving the full log stream
+				if _, err := io.Copy(io.Discard, reader); err != nil {
+					recordError(errors, "logs", err)
+					log.Warnf("Error reading log stream for %q: %v", name, err)
+				}
+				reader.Close()
+				durations["logs"] = time.Since(logStart)
 			case "metrics", "stats":
 				if reader, err := runner.Stats(statsCtx, ctr); err != nil {
-					errors["metrics"]++
+					recordError(errors, "metrics", err)
 					log.Warnf("Error during container command %q on %q: %v", cmd, name, err)
 				} else {
 					go func() {
@@ -224,13 +1078,51 @@ func (cb *CustomBench) runThread(ctx context.Context, runner driver.Driver, thre
 			default:
 				log.Errorf("Command %q unrecognized from YAML commands list; skipping", cmd)
 			}
+
+			releaseOp()
+			if prof != nil {
+				prof.stop()
+			}
+			if cmdCancel != nil {
+				cmdCancel()
+			}
+
+			// merge any fine-grained operation timings the driver recorded
+			// on the container (e.g. CRI pod vs container steps)
+			if timed, ok := ctr.(driver.OperationTimings); ok {
+				for key, opElapsed := range timed.Timings() {
+					durations[key] = opElapsed
+				}
+			}
+
+			if cb.commandDelay > 0 {
+				cb.delay(cb.commandDelay)
+			}
 		}
 
 		statsCancel()
 
+		// in cold-cache mode remove the image after each iteration so the
+		// next create measures a cold pull; drivers without image removal
+		// support simply skip this step
+		if cb.ColdCache {
+			if remover, ok := runner.(driver.ImageRemover); ok {
+				if err := remover.RemoveImage(ctx, image); err != nil {
+					log.Warnf("Cold-cache: failed to remove image %q: %v", image, err)
+				}
+			} else {
+				log.Warnf("Cold-cache: driver %s does not support image removal; skipping", runner.Type())
+			}
+		}
+
+		if cb.iterationDelay > 0 {
+			cb.delay(cb.iterationDelay)
+		}
+
 		stats <- RunStatistics{
 			Durations: durations,
 			Errors:    errors,
+			Retries:   retries,
 			Timestamp: time.Now().UTC(),
 		}
 	}
@@ -244,6 +1136,12 @@ func (cb *CustomBench) Stats() []RunStatistics {
 	return []RunStatistics{}
 }
 
+// Summary returns the bounded-memory aggregation of the run's iteration
+// statistics, or nil when raw statistics were retained
+func (cb *CustomBench) Summary() *RunSummary {
+	return cb.summary
+}
+
 // State returns Created, Running, or Completed
 func (cb *CustomBench) State() State {
 	return cb.state
@@ -0,0 +1,170 @@
+package benches
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/estesp/bucketbench/driver"
+	log "github.com/sirupsen/logrus"
+)
+
+// DensityBench ramps up to and maintains a target number of concurrently
+// running containers, then churn-replaces containers at steady state. This
+// exposes how create latency (and daemon overhead, when combined with the
+// overhead sampler) degrades with container density, which the serial
+// create/delete loop of CustomBench cannot show.
+type DensityBench struct {
+	*CustomBench
+	density int
+}
+
+// Run ramps each thread up to its share of the target density and then
+// performs the configured number of churn-replace iterations per thread
+func (db *DensityBench) Run(ctx context.Context, threads, iterations int, commands []string) error {
+	log.Infof("Start DensityBench run: threads (%d); density (%d); churn iterations (%d)", threads, db.density, iterations)
+	statChan := make([]chan RunStatistics, threads)
+	for i := range statChan {
+		statChan[i] = make(chan RunStatistics, iterations)
+	}
+	db.state = Running
+	start := time.Now()
+
+	// spread the density target across threads; earlier threads absorb
+	// any remainder
+	perThread := make([]int, threads)
+	for i := 0; i < db.density; i++ {
+		perThread[i%threads]++
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			db.runDensityThread(ctx, db.driver, index, perThread[index], iterations, statChan[index])
+		}(i)
+	}
+
+	threadStats := make([][]RunStatistics, threads)
+	var collectWg sync.WaitGroup
+	for i, ch := range statChan {
+		collectWg.Add(1)
+		go func(index int, ch chan RunStatistics) {
+			defer collectWg.Done()
+			for statEntry := range ch {
+				threadStats[index] = append(threadStats[index], statEntry)
+			}
+		}(i, ch)
+	}
+
+	wg.Wait()
+	db.elapsed = time.Since(start)
+
+	log.Infof("DensityBench threads complete in %v time elapsed", db.elapsed)
+	collectWg.Wait()
+	for _, entries := range threadStats {
+		db.stats = append(db.stats, entries...)
+	}
+	db.state = Completed
+	// final environment cleanup
+	if err := db.driver.Clean(ctx); err != nil {
+		return fmt.Errorf("Error during driver final cleanup: %v", err)
+	}
+	return nil
+}
+
+func (db *DensityBench) runDensityThread(ctx context.Context, runner driver.Driver, threadNum, target, iterations int, stats chan RunStatistics) {
+	defer close(stats)
+
+	running := make([]driver.Container, 0, target)
+
+	// ramp phase: create and start containers until this thread holds its
+	// share of the density target
+	for i := 0; i < target; i++ {
+		if ctx.Err() != nil {
+			return
+		}
+		name := fmt.Sprintf("%s-%d-r%d", driver.ContainerNamePrefix, threadNum, i)
+		ctr, ok := db.startContainer(ctx, runner, name, stats)
+		if !ok {
+			return
+		}
+		running = append(running, ctr)
+	}
+
+	if len(running) == 0 {
+		return
+	}
+
+	// churn phase: at steady-state density, replace one container per
+	// iteration so create latency is measured with the full load present
+	for i := 0; i < iterations; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+		slot := i % len(running)
+		victim := running[slot]
+
+		errors := make(map[string]int)
+		durations := make(map[string]time.Duration)
+		out, rmElapsed, err := runner.Remove(ctx, victim)
+		if err != nil {
+			errors["delete"]++
+			log.Warnf("Error removing container %q during churn: %v\n  Output: %s", victim.Name(), err, out)
+		}
+		durations["delete"] = rmElapsed
+		stats <- RunStatistics{
+			Durations: durations,
+			Errors:    errors,
+			Timestamp: time.Now().UTC(),
+		}
+
+		name := fmt.Sprintf("%s-%d-c%d", driver.ContainerNamePrefix, threadNum, i)
+		ctr, ok := db.startContainer(ctx, runner, name, stats)
+		if !ok {
+			break
+		}
+		running[slot] = ctr
+	}
+
+	// teardown: remove the containers this thread still holds
+	for _, ctr := range running {
+		if out, _, err := runner.Remove(ctx, ctr); err != nil {
+			log.Warnf("Error removing container %q during density teardown: %v\n  Output: %s", ctr.Name(), err, out)
+		}
+	}
+}
+
+// startContainer creates and runs one named container, reporting the "run"
+// duration (or error) on the stats channel
+func (cb *CustomBench) startContainer(ctx context.Context, runner driver.Driver, name string, stats chan RunStatistics) (driver.Container, bool) {
+	errors := make(map[string]int)
+	durations := make(map[string]time.Duration)
+
+	ctr, err := runner.Create(ctx, name, cb.imageInfo, cb.cmdOverride, true, cb.trace)
+	if err != nil {
+		log.Errorf("Error on creating container %q from image %q: %v", name, cb.imageInfo, err)
+		return nil, false
+	}
+
+	out, runElapsed, err := runner.Run(ctx, ctr)
+	if err != nil {
+		errors["run"]++
+		log.Warnf("Error running container %q: %v\n  Output: %s", name, err, out)
+	}
+	durations["run"] = runElapsed
+
+	stats <- RunStatistics{
+		Durations: durations,
+		Errors:    errors,
+		Timestamp: time.Now().UTC(),
+	}
+	return ctr, err == nil
+}
+
+// Type returns the type of benchmark
+func (db *DensityBench) Type() Type {
+	return Density
+}
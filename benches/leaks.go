@@ -0,0 +1,80 @@
+package benches
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/estesp/bucketbench/driver"
+	"github.com/shirou/gopsutil/process"
+	log "github.com/sirupsen/logrus"
+)
+
+// auditLeaks re-queries the driver (and the host) after Clean for
+// bb-labelled resources that survived teardown — containers, pods,
+// snapshots, shim processes, and mounts — and prints a leak report so
+// users notice when an engine fails to tear down under churn
+func auditLeaks(ctx context.Context, drv driver.Driver) {
+	var leaks []string
+
+	if auditor, ok := drv.(driver.LeakAuditor); ok {
+		found, err := auditor.AuditLeaks(ctx)
+		if err != nil {
+			log.Warnf("leak audit: driver query failed: %v", err)
+		}
+		leaks = append(leaks, found...)
+	}
+
+	leaks = append(leaks, leakedShimProcesses()...)
+	leaks = append(leaks, leakedMounts()...)
+
+	if len(leaks) == 0 {
+		log.Debug("leak audit: no leftover benchmark resources found")
+		return
+	}
+	log.Warnf("leak audit: %d leftover resource(s) after cleanup:", len(leaks))
+	for _, leak := range leaks {
+		log.Warnf("  %s", leak)
+	}
+}
+
+// leakedShimProcesses finds shim (or other per-container helper)
+// processes whose command line still references a benchmark container
+func leakedShimProcesses() []string {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil
+	}
+	var leaks []string
+	for _, proc := range procs {
+		cmdline, err := proc.Cmdline()
+		if err != nil || !strings.Contains(cmdline, driver.ContainerNamePrefix) {
+			continue
+		}
+		name, _ := proc.Name()
+		leaks = append(leaks, fmt.Sprintf("process %s (pid %d)", name, proc.Pid))
+	}
+	return leaks
+}
+
+// leakedMounts scans the mount table for entries still referencing a
+// benchmark container (e.g. an overlay or shm mount the engine failed to
+// unmount); non-Linux hosts without /proc simply skip the check
+func leakedMounts() []string {
+	data, err := os.ReadFile("/proc/self/mountinfo")
+	if err != nil {
+		return nil
+	}
+	var leaks []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.Contains(line, driver.ContainerNamePrefix) {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 4 {
+			leaks = append(leaks, "mount "+fields[4])
+		}
+	}
+	return leaks
+}
@@ -22,7 +22,7 @@ type LimitBench struct {
 }
 
 // Init initializes the benchmark
-func (lb *LimitBench) Init(_ context.Context, _ string, _ driver.Type, _, _, _ string, _ bool) error {
+func (lb *LimitBench) Init(_ context.Context, _ string, _ driver.Type, _, _ string, _ []string, _ bool) error {
 	return nil
 }
 
@@ -78,6 +78,11 @@ func (lb *LimitBench) Stats() []RunStatistics {
 	return []RunStatistics{}
 }
 
+// Summary returns nil; the limit benchmark never aggregates its statistics
+func (lb *LimitBench) Summary() *RunSummary {
+	return nil
+}
+
 // State returns Created, Running, or Completed
 func (lb *LimitBench) State() State {
 	return lb.state
@@ -0,0 +1,136 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package benches
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// leak verdict thresholds: growth slopes below these are attributed to
+	// caches and allocator noise rather than flagged as leaks; the memory
+	// threshold is in MiB/hour to match the sampler's units
+	leakMemMiBPerHour  = 16.0
+	leakThreadsPerHour = 1.0
+	leakFDsPerHour     = 10.0
+	// minimum series before a slope is considered meaningful; a short run
+	// gets an "inconclusive" verdict instead of a noisy extrapolation
+	leakMinSamples = 10
+	leakMinWindow  = 10 * time.Minute
+)
+
+// LongevityBench runs continuous container churn while the daemon sampler
+// collects memory, thread, and fd counts, then fits a linear slope to each
+// series and reports a leak verdict in units/hour. Memory growth under churn
+// is a frequent runtime bug class which short benchmark runs never surface;
+// this benchmark is meant to run for hours, typically via a 'duration:'
+// driver config.
+type LongevityBench struct {
+	*OverheadBench
+}
+
+// Run executes the churn via the overhead benchmark and evaluates the
+// collected daemon sample series for leaks afterwards
+func (lb *LongevityBench) Run(ctx context.Context, threads, iterations int, commands []string) error {
+	if err := lb.OverheadBench.Run(ctx, threads, iterations, commands); err != nil {
+		return err
+	}
+	lb.reportLeaks()
+	return nil
+}
+
+// Type returns the longevity benchmark type
+func (lb *LongevityBench) Type() Type {
+	return Longevity
+}
+
+// reportLeaks fits least-squares slopes to the daemon memory, thread, and fd
+// sample series and logs a per-resource leak verdict
+func (lb *LongevityBench) reportLeaks() {
+	var (
+		hours, mems, threads, fds []float64
+		start                     time.Time
+	)
+	for _, stat := range lb.Stats() {
+		if stat.Daemon == nil {
+			continue
+		}
+		if start.IsZero() {
+			start = stat.Timestamp
+		}
+		hours = append(hours, stat.Timestamp.Sub(start).Hours())
+		mems = append(mems, float64(stat.Daemon.Mem))
+		threads = append(threads, float64(stat.Daemon.Threads))
+		fds = append(fds, float64(stat.Daemon.FDs))
+	}
+
+	window := time.Duration(0)
+	if len(hours) > 0 {
+		window = time.Duration(hours[len(hours)-1] * float64(time.Hour))
+	}
+	if len(hours) < leakMinSamples || window < leakMinWindow {
+		log.Warnf("longevity: leak verdict inconclusive: only %d daemon samples over %s (need %d over %s)",
+			len(hours), window.Round(time.Second), leakMinSamples, leakMinWindow)
+		return
+	}
+
+	memSlope := slopePerHour(hours, mems)
+	threadSlope := slopePerHour(hours, threads)
+	fdSlope := slopePerHour(hours, fds)
+
+	log.Infof("longevity: daemon growth over %s: memory %+.1f MB/hour, threads %+.2f/hour, fds %+.2f/hour",
+		window.Round(time.Second), memSlope, threadSlope, fdSlope)
+
+	leaking := false
+	if memSlope > leakMemMiBPerHour {
+		log.Warnf("longevity: LEAK verdict: daemon memory growing %.1f MB/hour (threshold %.1f MB/hour)",
+			memSlope, leakMemMiBPerHour)
+		leaking = true
+	}
+	if threadSlope > leakThreadsPerHour {
+		log.Warnf("longevity: LEAK verdict: daemon thread/process count growing %.2f/hour (threshold %.2f/hour)",
+			threadSlope, leakThreadsPerHour)
+		leaking = true
+	}
+	if fdSlope > leakFDsPerHour {
+		log.Warnf("longevity: LEAK verdict: daemon fd count growing %.2f/hour (threshold %.2f/hour)",
+			fdSlope, leakFDsPerHour)
+		leaking = true
+	}
+	if !leaking {
+		log.Info("longevity: no leak detected: all growth slopes below thresholds")
+	}
+}
+
+// slopePerHour returns the least-squares slope of ys over xs (hours), giving
+// the fitted growth rate per hour of the sampled series
+func slopePerHour(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	var sumX, sumY, sumXY, sumXX float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+		sumXY += xs[i] * ys[i]
+		sumXX += xs[i] * xs[i]
+	}
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denom
+}
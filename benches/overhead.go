@@ -10,28 +10,111 @@ import (
 )
 
 const (
-	procMetricsSampleInterval = 500 * time.Millisecond
+	// default bounds for the adaptive sampling interval; short benchmarks are
+	// sampled near the minimum so each run still collects a meaningful number
+	// of samples, while long soak runs back off toward the maximum
+	overheadMinSampleInterval = 100 * time.Millisecond
+	overheadMaxSampleInterval = 5 * time.Second
+
+	// number of samples collected at a given interval before the interval
+	// is doubled (up to the configured maximum)
+	samplesPerBackoffStep = 20
 )
 
 // OverheadBench runs CustomBench benchmarks and measure memory and cpu usage of a container daemon
 type OverheadBench struct {
 	*CustomBench
-	cgroupPath string
+	cgroupPath  string
+	memMetric   string
+	minInterval time.Duration
+	maxInterval time.Duration
+	idlePeriod  time.Duration
+}
+
+// sampleIdleBaseline samples the daemon at the given interval for the
+// given period, before any workload starts, and returns the mean memory
+// and CPU usage observed; a canceled context cuts the period short
+func sampleIdleBaseline(ctx context.Context, sampler stats.Sampler, period, interval time.Duration) (uint64, float64) {
+	var (
+		memTotal uint64
+		cpuTotal float64
+		samples  int
+	)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	deadline := time.After(period)
+	for {
+		select {
+		case <-ticker.C:
+			result, err := sampler.Query()
+			if err != nil {
+				log.WithError(err).Debug("idle baseline sample failed")
+				continue
+			}
+			memTotal += result.Mem
+			cpuTotal += result.CPU
+			samples++
+		case <-deadline:
+			if samples == 0 {
+				return 0, 0
+			}
+			return memTotal / uint64(samples), cpuTotal / float64(samples)
+		case <-ctx.Done():
+			if samples == 0 {
+				return 0, 0
+			}
+			return memTotal / uint64(samples), cpuTotal / float64(samples)
+		}
+	}
 }
 
 // Run executes the benchmark iterations against a specific engine driver type
 // for a specified number of iterations
 func (b *OverheadBench) Run(ctx context.Context, threads, iterations int, commands []string) error {
-	sampler, err := stats.NewSampler(b.driver, b.cgroupPath)
+	cgroupPath := b.cgroupPath
+	discovered := false
+	if cgroupPath == "" && stats.HostCapabilities().CGroups {
+		// drivers that know their daemon's cgroup (e.g. containerd's
+		// systemd service slice) are sampled via the cgroup hierarchy by
+		// default, which also captures shim processes the proc-name walk
+		// would miss
+		if cg, ok := b.driver.(stats.DaemonCGrouper); ok {
+			cgroupPath = cg.DaemonCGroupPath()
+			discovered = true
+			log.Infof("sampling daemon overhead from cgroup %q", cgroupPath)
+		}
+	}
+	sampler, err := stats.NewSampler(b.driver, cgroupPath, b.memMetric)
+	if err != nil && discovered {
+		// the guessed unit may not exist (non-systemd host, renamed
+		// service); fall back to process sampling rather than failing
+		log.WithError(err).Warnf("daemon cgroup %q not usable; falling back to process sampling", cgroupPath)
+		sampler, err = stats.NewSampler(b.driver, "", b.memMetric)
+	}
 	if err != nil {
 		log.WithError(err).Error("failed to create stats sampler")
 		return err
 	}
 
+	var (
+		baseMem uint64
+		baseCPU float64
+	)
+	if b.idlePeriod > 0 {
+		log.Infof("sampling idle daemon baseline for %v before starting workload", b.idlePeriod)
+		baseMem, baseCPU = sampleIdleBaseline(ctx, sampler, b.idlePeriod, b.minInterval)
+		log.Infof("idle baseline: %d MB memory, %.2f%% CPU; reporting overhead as delta from idle", baseMem, baseCPU)
+	}
+
 	var metrics []RunStatistics
-	ticker := time.NewTicker(procMetricsSampleInterval)
+	// start sampling at the minimum interval so short benchmarks still get
+	// enough samples; the interval backs off as samples accumulate so soak
+	// runs don't collect an unbounded, oversized series
+	interval := b.minInterval
+	ticker := time.NewTicker(interval)
 
 	go func() {
+		samples := 0
 		for range ticker.C {
 			result, err := sampler.Query()
 			if err != nil {
@@ -39,12 +122,34 @@ func (b *OverheadBench) Run(ctx context.Context, threads, iterations int, comman
 				continue
 			}
 
+			// report usage relative to the pre-run idle footprint; samples
+			// below the baseline clamp at zero rather than going negative
+			if result.Mem > baseMem {
+				result.Mem -= baseMem
+			} else {
+				result.Mem = 0
+			}
+			result.CPU -= baseCPU
+			if result.CPU < 0 {
+				result.CPU = 0
+			}
+
 			stat := RunStatistics{
 				Timestamp: time.Now().UTC(),
 				Daemon:    result,
 			}
 
 			metrics = append(metrics, stat)
+
+			samples++
+			if samples%samplesPerBackoffStep == 0 && interval < b.maxInterval {
+				interval *= 2
+				if interval > b.maxInterval {
+					interval = b.maxInterval
+				}
+				log.Debugf("overhead sampling interval now %v after %d samples", interval, samples)
+				ticker.Reset(interval)
+			}
 		}
 	}()
 
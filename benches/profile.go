@@ -0,0 +1,78 @@
+package benches
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ProfileHook configures attaching a profiling tool to the daemon while a
+// named benchmark command runs, for a sampled subset of iterations; the
+// per-iteration output files are kept for offline analysis
+type ProfileHook struct {
+	// Tool is the profiler to attach: "perf" (perf record) or "strace"
+	// (strace -c syscall summary)
+	Tool string `yaml:"tool"`
+	// Command names the benchmark command to wrap (e.g. "run")
+	Command string `yaml:"command"`
+	// SampleEvery profiles every Nth iteration; every iteration when unset
+	SampleEvery int `yaml:"sampleEvery"`
+	// OutputDir is where per-iteration profile files are written; a
+	// bb-profiles directory under the system temp dir when unset
+	OutputDir string `yaml:"outputDir"`
+}
+
+// profiler wraps a perf or strace process attached to the daemon for the
+// duration of one benchmark command invocation
+type profiler struct {
+	cmd     *exec.Cmd
+	outPath string
+}
+
+// startProfiler attaches the hook's tool to the daemon pid, directing its
+// output to a file named for the wrapped command, thread, and iteration.
+// Attach is asynchronous, so the first moments of a very fast operation may
+// fall outside the captured window.
+func startProfiler(hook *ProfileHook, pid int, label string) (*profiler, error) {
+	outDir := hook.OutputDir
+	if outDir == "" {
+		outDir = filepath.Join(os.TempDir(), "bb-profiles")
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create profile output dir %q: %v", outDir, err)
+	}
+
+	var cmd *exec.Cmd
+	var outPath string
+	switch hook.Tool {
+	case "perf":
+		outPath = filepath.Join(outDir, label+".perf.data")
+		cmd = exec.Command("perf", "record", "-p", strconv.Itoa(pid), "-o", outPath)
+	case "strace":
+		outPath = filepath.Join(outDir, label+".strace")
+		cmd = exec.Command("strace", "-c", "-f", "-p", strconv.Itoa(pid), "-o", outPath)
+	default:
+		return nil, fmt.Errorf("unknown profile tool %q", hook.Tool)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %v", hook.Tool, err)
+	}
+	return &profiler{cmd: cmd, outPath: outPath}, nil
+}
+
+// stop detaches the profiler; both perf and strace finalize their output
+// file on interrupt
+func (p *profiler) stop() {
+	if err := p.cmd.Process.Signal(os.Interrupt); err != nil {
+		log.Warnf("failed to interrupt profiler: %v", err)
+		p.cmd.Process.Kill()
+	}
+	// exit status is expected to be non-zero after an interrupt
+	p.cmd.Wait()
+	log.Debugf("profile output saved to %s", p.outPath)
+}
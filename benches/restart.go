@@ -0,0 +1,158 @@
+package benches
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/estesp/bucketbench/driver"
+	"github.com/estesp/bucketbench/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// RestartBench creates a population of running containers, restarts the
+// engine daemon via systemd, and measures how long the daemon takes to come
+// back and reconcile every container — an operational recovery metric the
+// lifecycle loops never exercise
+type RestartBench struct {
+	*CustomBench
+	count int
+}
+
+const (
+	// reconcileProbeCmd is exec'd in each container to confirm the daemon
+	// can reach it again after the restart
+	reconcileProbeCmd = "echo bb-reconcile"
+	// restartReconcileTimeout bounds how long the benchmark waits for the
+	// daemon and all containers to become reachable after the restart
+	restartReconcileTimeout = 5 * time.Minute
+	// reconcilePollInterval is the delay between reconciliation probes
+	reconcilePollInterval = 500 * time.Millisecond
+)
+
+// Run starts the configured number of containers, restarts the daemon unit,
+// and records the restart duration plus the time until all containers
+// respond to an exec probe again
+func (rb *RestartBench) Run(ctx context.Context, threads, iterations int, commands []string) error {
+	log.Infof("Start RestartBench run: containers (%d); unit (%s)", rb.count, rb.daemonUnit)
+	statChan := make(chan RunStatistics, rb.count+1)
+	rb.state = Running
+	start := time.Now()
+
+	running := make([]driver.Container, 0, rb.count)
+	for i := 0; i < rb.count; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+		name := fmt.Sprintf("%s-0-%d", driver.ContainerNamePrefix, i)
+		ctr, ok := rb.startContainer(ctx, rb.driver, name, statChan)
+		if !ok {
+			break
+		}
+		running = append(running, ctr)
+	}
+
+	errors := make(map[string]int)
+	durations := make(map[string]time.Duration)
+
+	if len(running) > 0 && ctx.Err() == nil {
+		restartElapsed, err := rb.restartDaemon(ctx)
+		if err != nil {
+			recordError(errors, "restartDaemon", err)
+			log.Errorf("Error restarting unit %q: %v", rb.daemonUnit, err)
+		}
+		durations["restartDaemon"] = restartElapsed
+
+		if err == nil {
+			reconcileElapsed, rerr := rb.awaitReconciled(ctx, running)
+			if rerr != nil {
+				recordError(errors, "reconcile", rerr)
+				log.Errorf("Reconciliation incomplete after daemon restart: %v", rerr)
+			}
+			durations["reconcile"] = reconcileElapsed
+		}
+	}
+
+	statChan <- RunStatistics{
+		Durations: durations,
+		Errors:    errors,
+		Timestamp: time.Now().UTC(),
+	}
+	close(statChan)
+	for statEntry := range statChan {
+		rb.stats = append(rb.stats, statEntry)
+	}
+
+	rb.elapsed = time.Since(start)
+	log.Infof("RestartBench complete in %v time elapsed", rb.elapsed)
+	rb.state = Completed
+
+	// final environment cleanup
+	if err := rb.driver.Clean(ctx); err != nil {
+		return fmt.Errorf("Error during driver final cleanup: %v", err)
+	}
+	return nil
+}
+
+// restartDaemon restarts the daemon's systemd unit over D-Bus, returning
+// how long the systemd restart job took
+func (rb *RestartBench) restartDaemon(ctx context.Context) (time.Duration, error) {
+	controller, err := utils.NewSystemdController(ctx, rb.daemonUnit)
+	if err != nil {
+		return 0, err
+	}
+	defer controller.Close()
+	return controller.Restart(ctx)
+}
+
+// awaitReconciled waits until the daemon answers an info query and every
+// container responds to an exec probe, returning the elapsed time from the
+// end of the restart command to full reconciliation
+func (rb *RestartBench) awaitReconciled(ctx context.Context, running []driver.Container) (time.Duration, error) {
+	reconcileStart := time.Now()
+	deadline := reconcileStart.Add(restartReconcileTimeout)
+
+	// first wait for the daemon itself to answer again
+	for {
+		if err := ctx.Err(); err != nil {
+			return time.Since(reconcileStart), err
+		}
+		if !time.Now().Before(deadline) {
+			return time.Since(reconcileStart), fmt.Errorf("daemon not reachable within %v of restart", restartReconcileTimeout)
+		}
+		if _, err := rb.driver.Info(ctx); err == nil {
+			break
+		}
+		time.Sleep(reconcilePollInterval)
+	}
+
+	// then probe each container until all answer; containers already
+	// verified are not probed again
+	pending := make([]driver.Container, len(running))
+	copy(pending, running)
+	for len(pending) > 0 {
+		if err := ctx.Err(); err != nil {
+			return time.Since(reconcileStart), err
+		}
+		if !time.Now().Before(deadline) {
+			return time.Since(reconcileStart), fmt.Errorf("%d of %d containers not reconciled within %v of restart",
+				len(pending), len(running), restartReconcileTimeout)
+		}
+		remaining := pending[:0]
+		for _, ctr := range pending {
+			if _, _, err := rb.driver.Exec(ctx, ctr, reconcileProbeCmd); err != nil {
+				remaining = append(remaining, ctr)
+			}
+		}
+		pending = remaining
+		if len(pending) > 0 {
+			time.Sleep(reconcilePollInterval)
+		}
+	}
+	return time.Since(reconcileStart), nil
+}
+
+// Type returns the type of benchmark
+func (rb *RestartBench) Type() Type {
+	return Restart
+}
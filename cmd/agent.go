@@ -0,0 +1,54 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/estesp/bucketbench/agent"
+	"github.com/spf13/cobra"
+)
+
+var agentListenAddr string
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Run bucketbench as a remote benchmark agent",
+	Long: `Starts a gRPC server which executes benchmark runs on behalf of a
+coordinating 'bucketbench run --agents' invocation on another host. Drivers,
+images, and any rootfs paths referenced by the benchmark YAML must be
+available locally on the agent host.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		stopC := make(chan os.Signal, 1)
+		signal.Notify(stopC, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-stopC
+			cancel()
+		}()
+
+		return agent.Serve(ctx, agentListenAddr)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(agentCmd)
+	agentCmd.Flags().StringVar(&agentListenAddr, "listen", ":9981", "Address the agent listens on for coordinator connections")
+}
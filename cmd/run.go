@@ -15,19 +15,25 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"math"
+	"net/http"
+	_ "net/http/pprof"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
 	"syscall"
-	"text/tabwriter"
 	"time"
 
+	"github.com/estesp/bucketbench/agent"
+	"github.com/estesp/bucketbench/bench"
 	"github.com/estesp/bucketbench/benches"
 	"github.com/estesp/bucketbench/driver"
-	"github.com/montanaflynn/stats"
-	"github.com/pkg/errors"
+	bbstats "github.com/estesp/bucketbench/stats"
+	"github.com/estesp/bucketbench/utils"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	yaml "gopkg.in/yaml.v3"
@@ -36,7 +42,6 @@ import (
 const (
 	defaultLimitThreads = 10
 	defaultLimitIter    = 1000
-	limitBenchmarkName  = "Limit"
 )
 
 var (
@@ -44,40 +49,103 @@ var (
 	trace     bool
 	skipLimit bool
 	overhead  bool
+	density   bool
+	restart   bool
+	longevity bool
 	legacy    bool
+	preflight bool
+
+	// optional command line overrides of YAML-specified benchmark values
+	overrideImage      string
+	overrideDriver     string
+	overrideThreads    int
+	overrideIterations int
+	overrideCmdTimeout int
+
+	// optional file for the full timestamped overhead sample series
+	overheadSeriesFile string
+
+	// remote agent addresses for distributed coordinator mode
+	agents []string
+
+	// multi-host orchestration over plain SSH
+	sshInventoryFile string
+
+	// optional machine-readable export of the typed results
+	resultsJSONFile string
+
+	// optional upload of result artifacts to S3-compatible storage
+	s3Bucket    string
+	s3Endpoint  string
+	s3KeyPrefix string
+
+	// optional webhook notified when the run completes or fails
+	notifyURL string
+
+	// scheduled soak execution: re-run the benchmark on an interval
+	soakRepeat   int
+	soakInterval string
+
+	// self-profiling of the bucketbench process itself, for verifying the
+	// harness isn't the bottleneck at very high thread counts
+	pprofAddr       string
+	cpuProfileFile  string
+	heapProfileFile string
+
+	// harness isolation: keep the load generator off the CPUs the daemon
+	// under test is using
+	harnessCPUSet string
+	harnessProcs  int
+
+	// host quiescing between driver runs, so successive drivers start from
+	// comparable system states
+	quiesce    bool
+	dropCaches bool
+
+	// embedded registry serving a local OCI layout, for pull benchmarks
+	// independent of remote registry rate limits and WAN variance
+	localRegistryLayout string
+	localRegistryPort   int
 )
 
-// simple structure to handle collecting output data which will be displayed
-// after all benchmarks are complete
-type benchResult struct {
-	name        string
-	driverInfo  string
-	threads     int
-	iterations  int
-	threadRates []float64
-	statistics  [][]benches.RunStatistics
-}
-
-// simple structure to handle collecting output data which will be displayed
-// after one iteration benchmark is complete
-type benchSingleResult struct {
-	name       string
-	benchInfo  string
-	driverInfo string
-	threadRate float64
-	statistic  []benches.RunStatistics
-}
-
 var runCmd = &cobra.Command{
 	Use:   "run",
 	Short: "Run the benchmark against the selected container engine components",
 	Long: `The YAML file provided via the --benchmark flag will determine which
 lifecycle container commands to run against which container runtimes, specifying
 iterations and number of concurrent threads. Results will be displayed afterwards.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
+	RunE: func(cmd *cobra.Command, args []string) (retErr error) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
+		// apply harness isolation before any worker goroutines start so
+		// every thread inherits the restriction
+		if harnessProcs > 0 {
+			runtime.GOMAXPROCS(harnessProcs)
+		}
+		if harnessCPUSet != "" {
+			cpus, err := utils.ParseCPUSet(harnessCPUSet)
+			if err != nil {
+				return fmt.Errorf("Invalid cpuset %q: %v", harnessCPUSet, err)
+			}
+			if err := utils.PinToCPUs(cpus); err != nil {
+				return fmt.Errorf("Failed to pin harness to CPUs %q: %v", harnessCPUSet, err)
+			}
+			log.Infof("harness pinned to CPUs %s (GOMAXPROCS=%d)", harnessCPUSet, runtime.GOMAXPROCS(0))
+		}
+
+		// webhook notification fires whether the run completes or errors
+		// out partway through a long overnight session
+		var notifyResults []bench.Result
+		notifyStart := time.Now()
+		if notifyURL != "" {
+			defer func() {
+				if err := bench.Notify(notifyURL, notifyResults, retErr, time.Since(notifyStart)); err != nil {
+					log.Errorf("Error sending completion notification: %v", err)
+				}
+			}()
+		}
+
 		stopC := make(chan os.Signal, 1)
 		signal.Notify(stopC, os.Interrupt, syscall.SIGTERM)
 
@@ -90,32 +158,135 @@ iterations and number of concurrent threads. Results will be displayed afterward
 			}
 		}()
 
+		if pprofAddr != "" {
+			// expose net/http/pprof for live inspection of the harness
+			go func() {
+				log.Infof("pprof endpoint listening on %s", pprofAddr)
+				if err := http.ListenAndServe(pprofAddr, nil); err != nil {
+					log.Errorf("pprof endpoint failed: %v", err)
+				}
+			}()
+		}
+		if cpuProfileFile != "" {
+			cpuFile, err := os.Create(cpuProfileFile)
+			if err != nil {
+				return fmt.Errorf("can't create CPU profile file %q: %v", cpuProfileFile, err)
+			}
+			if err := pprof.StartCPUProfile(cpuFile); err != nil {
+				return fmt.Errorf("can't start CPU profile: %v", err)
+			}
+			defer func() {
+				pprof.StopCPUProfile()
+				cpuFile.Close()
+				log.Infof("CPU profile written to %s", cpuProfileFile)
+			}()
+		}
+		if heapProfileFile != "" {
+			defer func() {
+				heapFile, err := os.Create(heapProfileFile)
+				if err != nil {
+					log.Errorf("can't create heap profile file %q: %v", heapProfileFile, err)
+					return
+				}
+				defer heapFile.Close()
+				runtime.GC() // materialize current allocation statistics
+				if err := pprof.WriteHeapProfile(heapFile); err != nil {
+					log.Errorf("can't write heap profile: %v", err)
+					return
+				}
+				log.Infof("heap profile written to %s", heapProfileFile)
+			}()
+		}
+
 		if yamlFile == "" {
 			return fmt.Errorf("No YAML file provided with --benchmark/-b; nothing to do")
 		}
-		benchmark, err := readYaml(yamlFile)
+		benchmarks, err := readYaml(yamlFile)
 		if err != nil {
 			return fmt.Errorf("Error reading benchmark file %q: %v", yamlFile, err)
 		}
-		// verify that an image name exists in the benchmark as
-		// we'll end up erroring out further down if no image is
-		// specified
-		if benchmark.Image == "" {
-			return fmt.Errorf("Please provide an 'image:' entry in your benchmark YAML")
+		for i := range benchmarks {
+			applyOverrides(&benchmarks[i])
+		}
+		// validate the definitions up front (after flag overrides) so
+		// schema problems surface with a field name rather than as a
+		// zero-value run further down
+		for i := range benchmarks {
+			if err := benchmarks[i].Validate(); err != nil {
+				if benchmarks[i].Name != "" {
+					return fmt.Errorf("Invalid benchmark %q: %v", benchmarks[i].Name, err)
+				}
+				return fmt.Errorf("Invalid benchmark YAML %q: %v", yamlFile, err)
+			}
+		}
+
+		if localRegistryLayout != "" {
+			registry, err := bench.StartLocalRegistry(localRegistryLayout, localRegistryPort)
+			if err != nil {
+				return fmt.Errorf("Can't start local registry: %v", err)
+			}
+			defer registry.Close()
+			log.Infof("local registry serving %s at %s", localRegistryLayout, registry.Addr())
+			// point every image reference at the embedded registry so all
+			// pulls hit the seeded local content
+			for i := range benchmarks {
+				rewriteImages(&benchmarks[i], registry)
+			}
+		}
+
+		if preflight {
+			// verify daemons, binaries, bundles, and disk space before any
+			// benchmark work so misconfiguration surfaces as one report
+			checks := bench.Preflight(ctx, benchmarks)
+			if failed := bench.PrintPreflight(os.Stdout, checks); failed > 0 {
+				return fmt.Errorf("%d pre-flight check(s) failed; see report above", failed)
+			}
+		}
+
+		if sshInventoryFile != "" {
+			// multi-host mode: each inventory host runs the full benchmark
+			// remotely and the collected results are compared side by side
+			var extraArgs []string
+			if overhead {
+				extraArgs = append(extraArgs, "--overhead")
+			}
+			if legacy {
+				extraArgs = append(extraArgs, "--legacy")
+			}
+			hostResults, err := agent.RunSSH(ctx, sshInventoryFile, yamlFile, extraArgs)
+			if err != nil {
+				return err
+			}
+			hostMaxThreads := 0
+			for _, result := range hostResults {
+				hostMaxThreads = intMax(hostMaxThreads, result.Threads)
+			}
+			notifyResults = hostResults
+			bench.PrintReport(os.Stdout, hostMaxThreads, hostResults, overhead, legacy)
+			if _, err := bench.WriteGitHubSummary(hostResults); err != nil {
+				log.Errorf("Error writing GitHub Actions summary: %v", err)
+			}
+			if resultsJSONFile != "" {
+				if err := bench.WriteResults(resultsJSONFile, hostResults); err != nil {
+					log.Errorf("Error writing results JSON: %v", err)
+				}
+			}
+			log.Info("Multi-host benchmark runs complete")
+			return nil
 		}
 
 		var (
 			maxThreads = defaultLimitThreads
-			results    []benchResult
+			results    []bench.Result
 		)
 		if !skipLimit {
 			// get thread limit stats
 			limitRates := runLimitTest(ctx)
-			limitResult := benchResult{
-				name:        limitBenchmarkName,
-				threads:     defaultLimitThreads,
-				iterations:  defaultLimitIter,
-				threadRates: limitRates,
+			limitResult := bench.Result{
+				Name:        bench.LimitBenchmarkName,
+				Threads:     defaultLimitThreads,
+				Iterations:  defaultLimitIter,
+				ThreadRates: limitRates,
 			}
 			results = append(results, limitResult)
 		} else {
@@ -123,21 +294,183 @@ iterations and number of concurrent threads. Results will be displayed afterward
 		}
 
 		benchType := benches.Custom
-		if overhead {
+		modes := 0
+		for _, mode := range []bool{overhead, density, restart, longevity} {
+			if mode {
+				modes++
+			}
+		}
+		switch {
+		case modes > 1:
+			return fmt.Errorf("--overhead, --density, --restart, and --longevity cannot be combined")
+		case overhead:
 			benchType = benches.Overhead
+		case density:
+			benchType = benches.Density
+		case restart:
+			benchType = benches.Restart
+		case longevity:
+			benchType = benches.Longevity
 		}
 
-		for _, driverEntry := range benchmark.Drivers {
-			result, err := runBenchmark(ctx, benchType, driverEntry, benchmark, legacy)
+		// capture host-level conditions around the runs so the report can
+		// show whether the machine itself was under pressure
+		hostBefore, hostErr := bbstats.SampleHost()
+		if hostErr != nil {
+			log.Debugf("host metrics unavailable: %v", hostErr)
+		}
+		runStart := time.Now()
+
+		if soakRepeat < 1 {
+			soakRepeat = 1
+		}
+		var interval time.Duration
+		if soakInterval != "" {
+			interval, err = time.ParseDuration(soakInterval)
 			if err != nil {
-				return err
+				return fmt.Errorf("can't parse --interval value %q: %v", soakInterval, err)
+			}
+		}
+
+		// the first repetition's rates serve as the drift baseline for a
+		// soak run; a single-shot run makes exactly one pass through here
+		var baselineRates map[string]float64
+		for rep := 1; rep <= soakRepeat; rep++ {
+			if rep > 1 {
+				log.Infof("soak: waiting %s before repetition %d/%d", interval, rep, soakRepeat)
+				select {
+				case <-time.After(interval):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			var repResults []bench.Result
+			for _, benchmark := range benchmarks {
+				for _, driverEntry := range benchmark.OrderedDrivers() {
+					if dropCaches {
+						if err := bench.DropCaches(); err != nil {
+							log.Warnf("could not drop caches (requires root on Linux): %v", err)
+						}
+					}
+					if quiesce {
+						if err := bench.Quiesce(ctx); err != nil {
+							return err
+						}
+					}
+					opts := bench.Options{
+						BenchType: benchType,
+						Driver:    driverEntry,
+						Benchmark: benchmark,
+						Trace:     trace,
+						Legacy:    legacy,
+					}
+					// 'repetitions:' executes the entry several times and
+					// folds the runs into one combined result, logging the
+					// per-repetition rate spread
+					reps := benchmark.Repetitions
+					if reps < 1 {
+						reps = 1
+					}
+					var repRuns []bench.Result
+					for r := 1; r <= reps; r++ {
+						var result bench.Result
+						if len(agents) > 0 {
+							// coordinator mode: threads are divided across the remote
+							// agents and the merged result reported as one run
+							result, err = agent.RunDistributed(ctx, agents, opts)
+						} else {
+							result, err = bench.Run(ctx, opts)
+						}
+						if err != nil {
+							return err
+						}
+						if reps > 1 && len(result.ThreadRates) > 0 {
+							log.Infof("repetition %d/%d %s rate %.2f", r, reps, result.Name, result.ThreadRates[len(result.ThreadRates)-1])
+						}
+						repRuns = append(repRuns, result)
+					}
+					result := bench.CombineRepetitions(repRuns)
+					// when running a suite, qualify each result with its benchmark
+					// name so the combined report distinguishes the scenarios
+					if len(benchmarks) > 1 {
+						result.Name = fmt.Sprintf("%s:%s", benchmark.Name, result.Name)
+					}
+					repResults = append(repResults, result)
+					maxThreads = intMax(maxThreads, driverEntry.Threads)
+				}
+			}
+
+			if soakRepeat > 1 {
+				rates := make(map[string]float64)
+				for _, result := range repResults {
+					if len(result.ThreadRates) > 0 {
+						rates[result.Name] = result.ThreadRates[len(result.ThreadRates)-1]
+					}
+				}
+				if baselineRates == nil {
+					baselineRates = rates
+				} else {
+					for name, rate := range rates {
+						if base, ok := baselineRates[name]; ok && base > 0 {
+							log.Infof("soak: repetition %d/%d %s rate %.2f (%+.1f%% vs first)",
+								rep, soakRepeat, name, rate, 100*(rate-base)/base)
+						}
+					}
+				}
+				if resultsJSONFile != "" {
+					if err := bench.AppendResults(resultsJSONFile, rep, repResults); err != nil {
+						log.Errorf("Error appending to results store: %v", err)
+					}
+				}
+			}
+			// the final report and exports cover the last repetition
+			if rep == soakRepeat {
+				results = append(results, repResults...)
 			}
-			results = append(results, result)
-			maxThreads = intMax(maxThreads, driverEntry.Threads)
 		}
 
 		// output benchmark results
-		outputRunDetails(maxThreads, results, overhead, legacy)
+		notifyResults = results
+		bench.PrintReport(os.Stdout, maxThreads, results, overhead, legacy)
+
+		if written, err := bench.WriteGitHubSummary(results); err != nil {
+			log.Errorf("Error writing GitHub Actions summary: %v", err)
+		} else if written {
+			log.Info("Markdown summary written for GitHub Actions")
+		}
+
+		if resultsJSONFile != "" && soakRepeat == 1 {
+			// soak repetitions already appended to the results store
+			if err := bench.WriteResults(resultsJSONFile, results); err != nil {
+				log.Errorf("Error writing results JSON: %v", err)
+			} else {
+				log.Infof("Results JSON written to %s", resultsJSONFile)
+			}
+		}
+
+		if overheadSeriesFile != "" {
+			if err := bench.WriteOverheadSeries(overheadSeriesFile, results); err != nil {
+				log.Errorf("Error writing overhead series: %v", err)
+			} else {
+				log.Infof("Overhead sample series written to %s", overheadSeriesFile)
+			}
+		}
+
+		if s3Bucket != "" {
+			sink, err := bench.NewS3SinkFromEnv(s3Endpoint, s3Bucket, s3KeyPrefix)
+			if err != nil {
+				return err
+			}
+			if err := sink.UploadAll(ctx, resultsJSONFile, overheadSeriesFile); err != nil {
+				log.Errorf("Error uploading result artifacts: %v", err)
+			}
+		}
+
+		if hostErr == nil {
+			if hostAfter, err := bbstats.SampleHost(); err == nil {
+				outputHostMetrics(hostBefore, hostAfter, time.Since(runStart))
+			}
+		}
 
 		log.Info("Benchmark runs complete")
 		return nil
@@ -149,7 +482,7 @@ func runLimitTest(ctx context.Context) []float64 {
 	// get thread limit stats
 	for i := 1; i <= defaultLimitThreads; i++ {
 		limit, _ := benches.New(benches.Limit, &benches.DriverConfig{})
-		limit.Init(ctx, "", driver.Null, "", "", "", trace)
+		limit.Init(ctx, "", driver.Null, "", "", nil, trace)
 		limit.Run(ctx, i, defaultLimitIter, nil)
 		duration := limit.Elapsed()
 		rate := float64(i*defaultLimitIter) / duration.Seconds()
@@ -159,434 +492,207 @@ func runLimitTest(ctx context.Context) []float64 {
 	return rates
 }
 
-func runBenchmark(ctx context.Context, benchType benches.Type, driverConfig benches.DriverConfig, benchmark benches.Benchmark, legacyMode bool) (benchResult, error) {
-	var (
-		rates      []float64
-		stats      [][]benches.RunStatistics
-		benchInfo  string
-		driverInfo string
-	)
-
-	if legacyMode {
-		stats = make([][]benches.RunStatistics, driverConfig.Threads)
-		// Legacy mode in total run N test suites. for each test suite, it runs with n thread and n is the current thread numbers.
-		for i := 1; i <= driverConfig.Threads; i++ {
-			singleResult, err := runBenchmarkOnce(ctx, benchType, driverConfig, benchmark, i)
-			if err != nil {
-				return benchResult{}, err
-			}
-			benchInfo, driverInfo = singleResult.benchInfo, singleResult.driverInfo
-			rates = append(rates, singleResult.threadRate)
-			stats[i-1] = singleResult.statistic
-		}
-	} else {
-		stats = make([][]benches.RunStatistics, 1)
-		singleResult, err := runBenchmarkOnce(ctx, benchType, driverConfig, benchmark, driverConfig.Threads)
-		if err != nil {
-			return benchResult{}, err
-		}
-		benchInfo, driverInfo = singleResult.benchInfo, singleResult.driverInfo
-		rates = append(rates, singleResult.threadRate)
-		stats[0] = singleResult.statistic
-	}
-
-	result := benchResult{
-		name:        benchInfo,
-		driverInfo:  driverInfo,
-		threads:     driverConfig.Threads,
-		iterations:  driverConfig.Iterations,
-		threadRates: rates,
-		statistics:  stats,
-	}
-
-	return result, nil
-}
-
-// runBenchmark run exact one test suite
-func runBenchmarkOnce(ctx context.Context, benchType benches.Type, driverConfig benches.DriverConfig, benchmark benches.Benchmark, threads int) (benchSingleResult, error) {
-	bench, err := benches.New(benchType, &driverConfig)
-	if err != nil {
-		return benchSingleResult{}, err
-	}
-
-	driverType := driver.StringToType(driverConfig.Type)
-	imageInfo := benchmark.Image
-	if driverType == driver.Runc || driverType == driver.Ctr || driverType == driver.CRun || driverType == driver.Youki {
-		// legacy ctr mode, runc, crun and youki drivers need an exploded rootfs
-		// first, verify that a rootfs was provided in the benchmark YAML
-		if benchmark.RootFs == "" {
-			return benchSingleResult{}, fmt.Errorf("no rootfs defined in the benchmark YAML; driver %s requires a root FS path", driverConfig.Type)
-		}
-
-		imageInfo = benchmark.RootFs
-	}
-
-	err = bench.Init(ctx, benchmark.Name, driverType, driverConfig.ClientPath, imageInfo, benchmark.Command, trace)
-	if err != nil {
-		return benchSingleResult{}, err
-	}
-
-	benchInfo := fmt.Sprintf("%s:%s", benchType, driverConfig.Type)
-
-	if err = bench.Validate(ctx); err != nil {
-		return benchSingleResult{}, fmt.Errorf("error during bench validate: %v", err)
-	}
-
-	info, err := bench.Info(ctx)
-	if err != nil {
-		return benchSingleResult{}, errors.Wrap(err, "failed to query driver info")
-	}
-
-	driverInfo := info
-
-	err = bench.Run(ctx, threads, driverConfig.Iterations, benchmark.Commands)
-	if err != nil {
-		return benchSingleResult{}, fmt.Errorf("error during bench run: %v", err)
-	}
-
-	duration := bench.Elapsed()
-	rate := float64(threads*driverConfig.Iterations) / duration.Seconds()
-
-	result := benchSingleResult{
-		name:       benchInfo,
-		driverInfo: driverInfo,
-		benchInfo:  benchInfo,
-		threadRate: rate,
-		statistic:  bench.Stats(),
+// outputHostMetrics reports system-wide load, pressure-stall, and
+// context-switch numbers captured around the benchmark runs so readers can
+// judge whether the host was saturated while the timings were collected
+func outputHostMetrics(before, after *bbstats.HostMetrics, elapsed time.Duration) {
+	fmt.Printf("\nHOST CONDITIONS\n\n")
+	fmt.Printf("Load average (1/5/15m): %.2f/%.2f/%.2f before, %.2f/%.2f/%.2f after\n",
+		before.Load1, before.Load5, before.Load15,
+		after.Load1, after.Load5, after.Load15)
+	fmt.Printf("Pressure stall avg10 (cpu/mem/io): %.2f%%/%.2f%%/%.2f%%\n",
+		after.CPUPressure, after.MemoryPressure, after.IOPressure)
+	if after.ContextSwitches > before.ContextSwitches && elapsed.Seconds() > 0 {
+		rate := float64(after.ContextSwitches-before.ContextSwitches) / elapsed.Seconds()
+		fmt.Printf("Context switches: %.0f/sec over the run\n", rate)
 	}
-
-	log.Infof("%s: threads %d, iterations %d, rate: %6.2f", benchInfo, threads, driverConfig.Iterations, rate)
-	return result, nil
 }
 
-func getDelta(before, after float64) float64 {
-	switch {
-	case before != 0:
-		return after / before
-	case after == 0:
-		return 1
-	default:
-		return math.Inf(1)
+func intMax(x, y int) int {
+	if x > y {
+		return x
 	}
+	return y
 }
 
-func outputRunDetails(maxThreads int, results []benchResult, overhead bool, legacyMode bool) {
-	w := tabwriter.NewWriter(os.Stdout, 10, 4, 2, ' ', tabwriter.AlignRight)
-
-	fmt.Printf("\nSUMMARY TIMINGS/THREAD RATES\n\n")
-	fmt.Fprintf(w, " \tIter/Thd\t1 thrd")
-	for i := 2; i <= maxThreads; i++ {
-		fmt.Fprintf(w, "\t%d thrds", i)
-	}
-	fmt.Fprintln(w, "\t ")
-
-	for _, result := range results {
-		if legacyMode {
-			outputThreadRatesLegacy(w, result)
-		} else {
-			outputThreadRates(w, result)
-		}
-	}
-	w.Flush()
-	fmt.Println("")
-
-	cmdList := []string{"run", "pause", "resume", "stop", "delete"}
-	fmt.Printf("DETAILED COMMAND TIMINGS/STATISTICS\n")
-	// output per-command timings across the runs as well
-	for _, result := range results {
-		// only 1 result
-		if result.name == limitBenchmarkName {
-			// the limit "benchmark" has no detailed statistics
-			continue
-		}
-		if legacyMode {
-			outputDetailCommandStatsLegacy(result, w, cmdList)
-		} else {
-			outputDetailCommandStats(result, w, cmdList)
-		}
-
-		fmt.Println("")
-	}
-
-	w.Flush()
-
-	if overhead {
-		fmt.Fprintf(w, "\n")
-		fmt.Fprintf(w, "OVERHEAD\n")
-
-		var overheadResults []benchResult
-		for _, res := range results {
-			if res.name == limitBenchmarkName {
-				continue
+// applyOverrides replaces YAML-specified benchmark values with any provided
+// via command line flags, so quick experiments don't require editing the
+// benchmark definition file
+func applyOverrides(benchmark *benches.Benchmark) {
+	if overrideImage != "" {
+		benchmark.Image = overrideImage
+	}
+	if overrideDriver != "" {
+		// restrict the run to driver entries of the requested type; if the
+		// YAML has no entry for that type, retarget the first entry so its
+		// thread/iteration settings are reused
+		var matched []benches.DriverConfig
+		for _, driverEntry := range benchmark.Drivers {
+			if driverEntry.Type == overrideDriver {
+				matched = append(matched, driverEntry)
 			}
-			overheadResults = append(overheadResults, res)
 		}
-
-		if len(overheadResults) == 0 {
-			fmt.Fprint(w, "No data")
-			return
+		if len(matched) == 0 && len(benchmark.Drivers) > 0 {
+			retargeted := benchmark.Drivers[0]
+			retargeted.Type = overrideDriver
+			matched = append(matched, retargeted)
 		}
-
-		// Preprocess statistics before output
-		metrics := make([][]metricsResults, len(overheadResults))
-		for i, res := range overheadResults {
-			metrics[i] = make([]metricsResults, res.threads)
-			for j := 0; j < len(res.statistics); j++ {
-				metrics[i][j] = parseMetrics(res.statistics[j])
-			}
+		benchmark.Drivers = matched
+	}
+	for i := range benchmark.Drivers {
+		if overrideThreads > 0 {
+			benchmark.Drivers[i].Threads = overrideThreads
 		}
-
-		for i, res := range overheadResults {
-
-			fmt.Fprintf(w, "\n%s\n\n", res.driverInfo)
-
-			fmt.Fprintf(w, "Bench / driver / threads\tMin\tMax\tAvg\tMin\tMax\tAvg\tMem %%\tCPU x\t\n")
-
-			for j := 0; j < res.threads; j++ {
-				m := metrics[i][j]
-
-				fmt.Fprintf(w,
-					"%s:%d\t%d MB\t%d MB\t%d MB\t%.2f %%\t%.2f %%\t%.2f %%\t",
-					res.name, j+1,
-					m.minMem, m.maxMem, m.avgMem,
-					m.minCPU, m.maxCPU, m.avgCPU)
-
-				if i > 0 {
-					// Output overhead comparing to first result
-
-					if j < overheadResults[0].threads {
-						// Mem percent change, ranging from -100% up.
-						mem := 100*getDelta(float64(metrics[0][j].avgMem), float64(m.avgMem)) - 100
-						cpu := getDelta(metrics[0][j].avgCPU, m.avgCPU)
-
-						fmt.Fprintf(w, "%+.2f%%\t%.2fx\t", mem, cpu)
-					}
-				}
-
-				fmt.Fprint(w, "\n")
-			}
+		if overrideIterations > 0 {
+			benchmark.Drivers[i].Iterations = overrideIterations
 		}
-
-		w.Flush()
-	}
-}
-
-func outputDetailCommandStatsLegacy(result benchResult, w *tabwriter.Writer, cmdList []string) {
-	for i := 0; i < result.threads; i++ {
-		fmt.Fprintf(w, "%s:%d\tMin\tMax\tAvg\tMedian\tStddev\tErrors\t\n", result.name, i+1)
-		cmdTimings := parseStats(result.statistics[i])
-		// given we are working with a map, but we want consistent ordering in the output
-		// we walk a slice of commands in a natural/expected order and output stats for
-		// those that were used during the specific run
-		for _, cmd := range cmdList {
-			if stats, ok := cmdTimings[cmd]; ok {
-				fmt.Fprintf(w, "%s\t%6.2f\t%6.2f\t%6.2f\t%6.2f\t%6.2f\t%d\t\n", cmd, stats.min, stats.max, stats.avg, stats.median, stats.stddev, stats.errors)
-			}
+		if overrideCmdTimeout > 0 {
+			benchmark.Drivers[i].CommandTimeoutSec = overrideCmdTimeout
 		}
 	}
 }
 
-func outputDetailCommandStats(result benchResult, w *tabwriter.Writer, cmdList []string) {
-	fmt.Fprintf(w, "%s:%d\tMin\tMax\tAvg\tMedian\tStddev\tErrors\t\n", result.name, result.threads)
-	cmdTimings := parseStats(result.statistics[0])
-	for _, cmd := range cmdList {
-		if stats, ok := cmdTimings[cmd]; ok {
-			fmt.Fprintf(w, "%s\t%6.2f\t%6.2f\t%6.2f\t%6.2f\t%6.2f\t%d\t\n", cmd, stats.min, stats.max, stats.avg, stats.median, stats.stddev, stats.errors)
+// rewriteImages points every image reference in a benchmark (including
+// per-driver overrides and image rotation lists) at the local registry
+func rewriteImages(benchmark *benches.Benchmark, registry *bench.LocalRegistry) {
+	benchmark.Image = registry.RewriteImageRef(benchmark.Image)
+	for i := range benchmark.Images {
+		benchmark.Images[i] = registry.RewriteImageRef(benchmark.Images[i])
+	}
+	for i := range benchmark.Drivers {
+		benchmark.Drivers[i].Image = registry.RewriteImageRef(benchmark.Drivers[i].Image)
+		for j := range benchmark.Drivers[i].Images {
+			benchmark.Drivers[i].Images[j] = registry.RewriteImageRef(benchmark.Drivers[i].Images[j])
 		}
 	}
 }
 
-func outputThreadRates(w *tabwriter.Writer, result benchResult) {
-	if result.name == limitBenchmarkName {
-		outputThreadRatesLegacy(w, result)
-		return
-	}
-
-	fmt.Fprintf(w, "%s\t%d", result.name, result.iterations)
-	for i := 1; i <= result.threads; i++ {
-		fmt.Fprintf(w, "\t")
-	}
-	fmt.Fprintf(w, "%7.2f\t ", result.threadRates[0])
-}
-
-func outputThreadRatesLegacy(w *tabwriter.Writer, result benchResult) {
-	fmt.Fprintf(w, "%s\t%d\t%7.2f", result.name, result.iterations, result.threadRates[0])
-	for i := 1; i < result.threads; i++ {
-		fmt.Fprintf(w, "\t%7.2f", result.threadRates[i])
-	}
-	fmt.Fprintln(w, "\t ")
-}
-
-type metricsResults struct {
-	minMem uint64
-	maxMem uint64
-	avgMem uint64
-	minCPU float64
-	maxCPU float64
-	avgCPU float64
+// benchmarkSuite is the object form of a YAML suite file holding multiple
+// named benchmark definitions to be executed sequentially in one invocation
+type benchmarkSuite struct {
+	// Include lists additional YAML files (relative to the suite file)
+	// whose 'profiles:' entries become available to this suite, so shared
+	// fragments can live in one place across many suites
+	Include []string `yaml:"include"`
+	// Profiles holds named benchmark fragments; a benchmark selects one
+	// via 'profile:' and starts from its fields, overriding any it sets
+	// itself
+	Profiles map[string]yaml.Node `yaml:"profiles"`
+	// Benchmarks is kept as raw nodes so each entry can be decoded on top
+	// of its selected profile
+	Benchmarks []yaml.Node
 }
 
-func parseMetrics(metrics []benches.RunStatistics) metricsResults {
-	var mems []float64
-	var cpus []float64
-
-	metrics = filterStats(metrics, func(stat benches.RunStatistics) bool {
-		return stat.Daemon != nil
-	})
-
-	for _, m := range metrics {
-		mems = append(mems, float64(m.Daemon.Mem))
-		cpus = append(cpus, m.Daemon.CPU)
-	}
-
-	minMem, err := stats.Min(mems)
-	if err != nil {
-		log.Errorf("error finding min mem: %v", err)
-	}
-
-	maxMem, err := stats.Max(mems)
+// strictDecodeNode decodes a YAML node with unknown-field detection; used
+// for profile fragments and benchmark entries, which are decoded in two
+// passes over the same struct so benchmark fields override profile fields
+func strictDecodeNode(node *yaml.Node, out interface{}) error {
+	data, err := yaml.Marshal(node)
 	if err != nil {
-		log.Errorf("error finding max mem: %v", err)
+		return err
 	}
-
-	avgMem, err := stats.Mean(mems)
-	if err != nil {
-		log.Errorf("error finding avg mem: %v", err)
-	}
-
-	minCPU, err := stats.Min(cpus)
-	if err != nil {
-		log.Errorf("error finding min cpu: %v", err)
-	}
-
-	maxCPU, err := stats.Max(cpus)
-	if err != nil {
-		log.Errorf("error finding max cpu: %v", err)
-	}
-
-	avgCPU, err := stats.Mean(cpus)
-	if err != nil {
-		log.Errorf("error finding avg cpu: %v", err)
-	}
-
-	return metricsResults{
-		minMem: uint64(minMem),
-		maxMem: uint64(maxMem),
-		avgMem: uint64(avgMem),
-		minCPU: minCPU,
-		maxCPU: maxCPU,
-		avgCPU: avgCPU,
-	}
-}
-
-type statResults struct {
-	min    float64
-	max    float64
-	avg    float64
-	median float64
-	stddev float64
-	errors int
-}
-
-func filterStats(stats []benches.RunStatistics, check func(benches.RunStatistics) bool) (ret []benches.RunStatistics) {
-	for _, stat := range stats {
-		if check(stat) {
-			ret = append(ret, stat)
-		}
-	}
-
-	return
+	return strictUnmarshal(data, out)
 }
 
-func parseStats(statistics []benches.RunStatistics) map[string]statResults {
-	result := make(map[string]statResults)
-	durationSeq := make(map[string][]float64)
-	errorSeq := make(map[string][]int)
-
-	statistics = filterStats(statistics, func(stat benches.RunStatistics) bool {
-		return stat.Daemon == nil
-	})
-
-	iterations := len(statistics)
-
-	durationKeys := make([]string, len(statistics[0].Durations))
-	i := 0
-	for k := range statistics[0].Durations {
-		durationKeys[i] = k
-		i++
-	}
-	for i := 0; i < iterations; i++ {
-		for key, duration := range statistics[i].Durations {
-			durationSeq[key] = append(durationSeq[key], float64(duration.Nanoseconds()/int64(time.Millisecond)))
+// gatherProfiles collects the named fragments visible to a suite: those from
+// its included files (in order) and then its own, later names overriding
+// earlier ones
+func gatherProfiles(filename string, suite *benchmarkSuite) (map[string]*yaml.Node, error) {
+	profiles := make(map[string]*yaml.Node)
+	for _, include := range suite.Include {
+		path := include
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(filename), path)
 		}
-		for key, errors := range statistics[i].Errors {
-			errorSeq[key] = append(errorSeq[key], errors)
-		}
-	}
-	for _, key := range durationKeys {
-		// take the durations for this key and perform
-		// several math/statistical functions:
-		min, err := stats.Min(durationSeq[key])
-		if err != nil {
-			log.Errorf("Error finding stats.Min(): %v", err)
-		}
-		max, err := stats.Max(durationSeq[key])
-		if err != nil {
-			log.Errorf("Error finding stats.Max(): %v", err)
-		}
-		average, err := stats.Mean(durationSeq[key])
-		if err != nil {
-			log.Errorf("Error finding stats.Average(): %v", err)
-		}
-		median, err := stats.Median(durationSeq[key])
+		data, err := os.ReadFile(path)
 		if err != nil {
-			log.Errorf("Error finding stats.Median(): %v", err)
+			return nil, fmt.Errorf("can't read included file %q: %v", include, err)
 		}
-		stddev, err := stats.StandardDeviation(durationSeq[key])
-		if err != nil {
-			log.Errorf("Error finding stats.StdDev(): %v", err)
+		var included struct {
+			Profiles map[string]yaml.Node `yaml:"profiles"`
 		}
-		var errors int
-		if errorSlice, ok := errorSeq[key]; ok {
-			errors = intSum(errorSlice)
+		if err := yaml.Unmarshal(data, &included); err != nil {
+			return nil, fmt.Errorf("can't unmarshal included file %q: %v", include, err)
 		}
-		result[key] = statResults{
-			min:    min,
-			max:    max,
-			avg:    average,
-			median: median,
-			stddev: stddev,
-			errors: errors,
+		for name, node := range included.Profiles {
+			node := node
+			profiles[name] = &node
 		}
 	}
-	return result
+	for name, node := range suite.Profiles {
+		node := node
+		profiles[name] = &node
+	}
+	return profiles, nil
 }
 
-func intSum(slice []int) int {
-	var total int
-	for _, val := range slice {
-		total += val
+// resolveBenchmark builds one suite entry: the selected profile fragment (if
+// any) is decoded first and the entry's own fields are decoded over it
+func resolveBenchmark(node *yaml.Node, index int, profiles map[string]*yaml.Node) (benches.Benchmark, error) {
+	var benchmark benches.Benchmark
+	var selector struct {
+		Profile string `yaml:"profile"`
+	}
+	// a decode problem here will be reported by the strict pass below
+	_ = node.Decode(&selector)
+	if selector.Profile != "" {
+		profile, ok := profiles[selector.Profile]
+		if !ok {
+			return benchmark, fmt.Errorf("benchmarks[%d] references unknown profile %q", index, selector.Profile)
+		}
+		if err := strictDecodeNode(profile, &benchmark); err != nil {
+			return benchmark, fmt.Errorf("profile %q: %v", selector.Profile, err)
+		}
 	}
-	return total
-}
-func intMax(x, y int) int {
-	if x > y {
-		return x
+	if err := strictDecodeNode(node, &benchmark); err != nil {
+		return benchmark, fmt.Errorf("benchmarks[%d]: %v", index, err)
 	}
-	return y
+	return benchmark, nil
 }
 
-func readYaml(filename string) (benches.Benchmark, error) {
-	var benchmarkYaml benches.Benchmark
+// strictUnmarshal decodes YAML with unknown-field detection turned on, so a
+// typo'd key fails loudly instead of silently running with default values
+func strictUnmarshal(data []byte, out interface{}) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	return decoder.Decode(out)
+}
+
+func readYaml(filename string) ([]benches.Benchmark, error) {
 	yamlFile, err := os.ReadFile(filename)
 	if err != nil {
-		return benchmarkYaml, fmt.Errorf("Can't read YAML file %q: %v", filename, err)
+		return nil, fmt.Errorf("Can't read YAML file %q: %v", filename, err)
+	}
+	// a suite file has a top-level 'benchmarks:' list; sniff the shape
+	// non-strictly first so strict decoding errors report against the
+	// schema the file actually uses
+	var probe struct {
+		Benchmarks []yaml.Node `yaml:"benchmarks"`
+	}
+	if yaml.Unmarshal(yamlFile, &probe) == nil && len(probe.Benchmarks) > 0 {
+		var suite benchmarkSuite
+		if err = strictUnmarshal(yamlFile, &suite); err != nil {
+			return nil, fmt.Errorf("Can't unmarshal benchmark suite %q: %v", filename, err)
+		}
+		profiles, err := gatherProfiles(filename, &suite)
+		if err != nil {
+			return nil, err
+		}
+		benchmarks := make([]benches.Benchmark, 0, len(suite.Benchmarks))
+		for i := range suite.Benchmarks {
+			benchmark, err := resolveBenchmark(&suite.Benchmarks[i], i, profiles)
+			if err != nil {
+				return nil, err
+			}
+			benchmarks = append(benchmarks, benchmark)
+		}
+		return benchmarks, nil
 	}
-	err = yaml.Unmarshal(yamlFile, &benchmarkYaml)
-	if err != nil {
-		return benchmarkYaml, fmt.Errorf("Can't unmarshal YAML file %q: %v", filename, err)
+	var benchmarkYaml benches.Benchmark
+	if err = strictUnmarshal(yamlFile, &benchmarkYaml); err != nil {
+		return nil, fmt.Errorf("Can't unmarshal YAML file %q: %v", filename, err)
 	}
-	return benchmarkYaml, nil
+	return []benches.Benchmark{benchmarkYaml}, nil
 }
 
 func init() {
@@ -595,5 +701,33 @@ func init() {
 	runCmd.PersistentFlags().BoolVarP(&trace, "trace", "t", false, "Enable per-container tracing during benchmark runs")
 	runCmd.PersistentFlags().BoolVarP(&skipLimit, "skip-limit", "s", false, "Skip 'limit' benchmark run")
 	runCmd.PersistentFlags().BoolVarP(&overhead, "overhead", "o", false, "Output daemon overhead")
+	runCmd.PersistentFlags().BoolVarP(&density, "density", "d", false, "Run the steady-state density benchmark (requires 'density:' in driver config)")
+	runCmd.PersistentFlags().BoolVarP(&restart, "restart", "r", false, "Run the daemon restart/reconcile benchmark (requires 'density:' in driver config)")
+	runCmd.PersistentFlags().BoolVar(&longevity, "longevity", false, "Run the longevity/leak-detection benchmark (best with a 'duration:' driver config)")
 	runCmd.PersistentFlags().BoolVarP(&legacy, "legacy", "l", false, "legacy mode will run benchmark from 1 to N(thread number) iterations.")
+	runCmd.PersistentFlags().BoolVar(&preflight, "preflight", false, "Verify daemons, binaries, bundles, and disk space before running; abort on failures")
+	runCmd.PersistentFlags().StringVar(&overrideImage, "image", "", "Override the 'image:' entry in the benchmark YAML")
+	runCmd.PersistentFlags().StringVar(&overrideDriver, "driver", "", "Run only the named driver type, overriding the YAML drivers list")
+	runCmd.PersistentFlags().IntVar(&overrideThreads, "threads", 0, "Override the 'threads:' value for all driver entries")
+	runCmd.PersistentFlags().IntVar(&overrideIterations, "iterations", 0, "Override the 'iterations:' value for all driver entries")
+	runCmd.PersistentFlags().IntVar(&overrideCmdTimeout, "command-timeout", 0, "Override the 'commandTimeoutSec:' value for all driver entries")
+	runCmd.PersistentFlags().StringVar(&overheadSeriesFile, "overhead-series", "", "Write the timestamped overhead sample series to this file (JSON for .json, CSV otherwise)")
+	runCmd.PersistentFlags().StringSliceVar(&agents, "agents", nil, "Dispatch benchmark threads to these remote 'bucketbench agent' addresses instead of running locally")
+	runCmd.PersistentFlags().StringVar(&sshInventoryFile, "ssh-inventory", "", "Run the benchmark on every SSH host listed in this inventory file and combine the results")
+	runCmd.PersistentFlags().StringVar(&resultsJSONFile, "results-json", "", "Write the full typed benchmark results as JSON to this file")
+	runCmd.PersistentFlags().StringVar(&s3Bucket, "s3-bucket", "", "Upload result artifact files (--results-json, --overhead-series) to this S3 bucket")
+	runCmd.PersistentFlags().StringVar(&s3Endpoint, "s3-endpoint", "https://s3.amazonaws.com", "Endpoint of the S3-compatible object store")
+	runCmd.PersistentFlags().StringVar(&s3KeyPrefix, "s3-key-prefix", "bucketbench/{hostname}/{date}-{time}", "Key prefix for uploaded artifacts; {hostname}, {date}, {time}, and {unix} are expanded")
+	runCmd.PersistentFlags().StringVar(&notifyURL, "notify-url", "", "POST a completion summary (Slack-compatible) to this webhook when the run finishes or fails")
+	runCmd.PersistentFlags().IntVar(&soakRepeat, "repeat", 1, "Re-execute the benchmark this many times for soak/stability tracking")
+	runCmd.PersistentFlags().StringVar(&soakInterval, "interval", "", "Wait this long between soak repetitions (e.g. '1h'); requires --repeat > 1")
+	runCmd.PersistentFlags().StringVar(&pprofAddr, "pprof", "", "Expose net/http/pprof for the bucketbench process on this address (e.g. ':6060')")
+	runCmd.PersistentFlags().StringVar(&cpuProfileFile, "cpu-profile", "", "Write a CPU profile of the bucketbench process to this file")
+	runCmd.PersistentFlags().StringVar(&heapProfileFile, "heap-profile", "", "Write a heap profile of the bucketbench process to this file at run end")
+	runCmd.PersistentFlags().StringVar(&harnessCPUSet, "cpuset", "", "Pin the bucketbench process to these CPUs (e.g. '0-3,8') to keep it off the daemon's CPUs (Linux only)")
+	runCmd.PersistentFlags().IntVar(&harnessProcs, "gomaxprocs", 0, "Limit GOMAXPROCS for the bucketbench process")
+	runCmd.PersistentFlags().BoolVar(&quiesce, "quiesce", false, "Wait for host load and dirty page volume to settle before each driver run")
+	runCmd.PersistentFlags().BoolVar(&dropCaches, "drop-caches", false, "Drop the kernel page cache before each driver run (requires root on Linux)")
+	runCmd.PersistentFlags().StringVar(&localRegistryLayout, "local-registry", "", "Serve the OCI image layout at this path as a local registry and pull benchmark images from it")
+	runCmd.PersistentFlags().IntVar(&localRegistryPort, "local-registry-port", 0, "Port for the local registry (default: an ephemeral port)")
 }
@@ -0,0 +1,123 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RegistryAuth configures credentials for image pulls from private
+// registries. Resolution order: explicit username/password, then the
+// REGISTRY_USERNAME/REGISTRY_PASSWORD environment variables, then (when
+// enabled) the registry's entry in the Docker CLI config.json.
+type RegistryAuth struct {
+	Username string
+	Password string
+	// UseDockerConfig falls back to the credentials stored for the image's
+	// registry in $DOCKER_CONFIG/config.json (or ~/.docker/config.json)
+	UseDockerConfig bool
+}
+
+// Resolve returns the credentials to use for pulling the given image
+// reference; empty credentials mean an anonymous pull
+func (a *RegistryAuth) Resolve(image string) (string, string, error) {
+	if a == nil {
+		return "", "", nil
+	}
+	if a.Username != "" {
+		return a.Username, a.Password, nil
+	}
+	if username := os.Getenv("REGISTRY_USERNAME"); username != "" {
+		return username, os.Getenv("REGISTRY_PASSWORD"), nil
+	}
+	if a.UseDockerConfig {
+		return dockerConfigCreds(registryHost(image))
+	}
+	return "", "", nil
+}
+
+// registryHost extracts the registry component of an image reference; a
+// first path segment with a dot, colon, or "localhost" names a registry,
+// anything else resolves to Docker Hub
+func registryHost(image string) string {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return parts[0]
+	}
+	return "docker.io"
+}
+
+// dockerConfigFile is the subset of the Docker CLI config.json holding
+// stored registry credentials
+type dockerConfigFile struct {
+	Auths map[string]struct {
+		Auth     string `json:"auth"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"auths"`
+}
+
+// dockerConfigCreds looks up stored credentials for a registry host in the
+// Docker CLI configuration, decoding the base64 "user:pass" auth form
+func dockerConfigCreds(host string) (string, string, error) {
+	configDir := os.Getenv("DOCKER_CONFIG")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", err
+		}
+		configDir = filepath.Join(home, ".docker")
+	}
+	data, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+	if err != nil {
+		return "", "", fmt.Errorf("can't read Docker config for registry credentials: %v", err)
+	}
+	var config dockerConfigFile
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", "", fmt.Errorf("can't parse Docker config: %v", err)
+	}
+
+	// Docker Hub credentials are stored under the legacy index URL
+	keys := []string{host}
+	if host == "docker.io" {
+		keys = append(keys, "https://index.docker.io/v1/", "index.docker.io", "registry-1.docker.io")
+	}
+	for _, key := range keys {
+		entry, ok := config.Auths[key]
+		if !ok {
+			continue
+		}
+		if entry.Username != "" {
+			return entry.Username, entry.Password, nil
+		}
+		if entry.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return "", "", fmt.Errorf("can't decode stored auth for %q: %v", key, err)
+			}
+			parts := strings.SplitN(string(decoded), ":", 2)
+			if len(parts) != 2 {
+				return "", "", fmt.Errorf("malformed stored auth for %q", key)
+			}
+			return parts[0], parts[1], nil
+		}
+	}
+	return "", "", fmt.Errorf("no stored credentials for registry %q in Docker config", host)
+}
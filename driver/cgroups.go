@@ -0,0 +1,119 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driver
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// statePid extracts the container init process id from an OCI runtime
+// `state` JSON blob (runc/crun/youki all emit a top-level "pid" field)
+func statePid(stateJSON string) (int, error) {
+	var state struct {
+		Pid int `json:"pid"`
+	}
+	if err := json.Unmarshal([]byte(stateJSON), &state); err != nil {
+		return 0, fmt.Errorf("failed parsing runtime state output: %v", err)
+	}
+	if state.Pid <= 0 {
+		return 0, fmt.Errorf("runtime state reports no running process")
+	}
+	return state.Pid, nil
+}
+
+// cgroupMetricsForPID takes a one-shot reading of memory (bytes) and
+// cumulative CPU (nanoseconds) usage from the cgroup of the given
+// process, handling both the unified (v2) and legacy (v1) hierarchies.
+// The OCI-binary drivers use this for container measurement since they
+// have no daemon to query for stats.
+func cgroupMetricsForPID(pid int) (memBytes, cpuNanos uint64, err error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed reading cgroup of pid %d: %v", pid, err)
+	}
+	var memPath, cpuPath string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			// unified (v2) hierarchy: a single entry covers all controllers
+			base := "/sys/fs/cgroup" + parts[2]
+			mem, err := readUintFile(base + "/memory.current")
+			if err != nil {
+				return 0, 0, err
+			}
+			cpu, err := cgroupV2CPUNanos(base + "/cpu.stat")
+			if err != nil {
+				return 0, 0, err
+			}
+			return mem, cpu, nil
+		}
+		for _, ctrl := range strings.Split(parts[1], ",") {
+			switch ctrl {
+			case "memory":
+				memPath = "/sys/fs/cgroup/memory" + parts[2]
+			case "cpuacct":
+				cpuPath = "/sys/fs/cgroup/cpuacct" + parts[2]
+			}
+		}
+	}
+	if memPath == "" || cpuPath == "" {
+		return 0, 0, fmt.Errorf("no memory/cpuacct cgroup found for pid %d", pid)
+	}
+	mem, err := readUintFile(memPath + "/memory.usage_in_bytes")
+	if err != nil {
+		return 0, 0, err
+	}
+	cpu, err := readUintFile(cpuPath + "/cpuacct.usage")
+	if err != nil {
+		return 0, 0, err
+	}
+	return mem, cpu, nil
+}
+
+// readUintFile reads a single unsigned integer value from a cgroup file
+func readUintFile(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// cgroupV2CPUNanos reads total CPU usage from a v2 cpu.stat file,
+// converting the usage_usec field to nanoseconds to match the v1 unit
+func cgroupV2CPUNanos(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "usage_usec" {
+			usec, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0, err
+			}
+			return usec * 1000, nil
+		}
+	}
+	return 0, fmt.Errorf("usage_usec not found in %s", path)
+}
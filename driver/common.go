@@ -4,7 +4,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/estesp/bucketbench/utils"
 )
 
 // Type represents the know implementations of the driver interface
@@ -55,7 +60,7 @@ type Container interface {
 
 	// Command returns an optional command that overrides the default image
 	// "CMD" or "ENTRYPOINT" for the Docker and Containerd (gRPC) drivers
-	Command() string
+	Command() []string
 
 	//GetPodID returns podid associated with the container
 	//only used by CRI-based drivers
@@ -75,9 +80,14 @@ type Driver interface {
 	// Path returns the binary (or socket) path related to the runtime in use
 	Path() string
 
+	// Capabilities reports which optional operations this driver actually
+	// supports, so benchmarks can skip or flag unsupported commands
+	// up-front instead of timing no-ops
+	Capabilities() Capabilities
+
 	// Create will create a container instance matching the specific needs
 	// of a driver
-	Create(ctx context.Context, name, image, cmdOverride string, detached bool, trace bool) (Container, error)
+	Create(ctx context.Context, name, image string, cmdOverride []string, detached bool, trace bool) (Container, error)
 
 	// Clean will clean the operating environment of a specific driver
 	Clean(ctx context.Context) error
@@ -100,6 +110,15 @@ type Driver interface {
 	// Wait blocks thread until container stop
 	Wait(ctx context.Context, ctr Container) (string, time.Duration, error)
 
+	// Exec will execute a command inside a running container
+	Exec(ctx context.Context, ctr Container, cmd string) (string, time.Duration, error)
+
+	// Checkpoint will checkpoint a running container's state via CRIU
+	Checkpoint(ctx context.Context, ctr Container) (string, time.Duration, error)
+
+	// Restore will restore a previously checkpointed container
+	Restore(ctx context.Context, ctr Container) (string, time.Duration, error)
+
 	// Close allows the driver to free any resources/close any
 	// connections
 	Close() error
@@ -112,9 +131,235 @@ type Driver interface {
 
 	// Stats returns a reader with streaming data output
 	Stats(ctx context.Context, ctr Container) (io.ReadCloser, error)
+
+	// Logs returns a reader with the container's log output
+	Logs(ctx context.Context, ctr Container) (io.ReadCloser, error)
+}
+
+// cleanupConcurrency bounds how many leftover containers a driver Clean()
+// tears down in parallel; enough to make large density-run cleanups fast
+// without flooding the engine with requests
+const cleanupConcurrency = 8
+
+// cleanupParallel invokes fn for each index in [0,n) with bounded
+// concurrency and waits for all invocations to finish; used by driver
+// Clean() implementations to remove leftover containers in parallel
+func cleanupParallel(n int, fn func(i int)) {
+	sem := make(chan struct{}, cleanupConcurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// waitPollInterval is the delay between state queries when an OCI-binary
+// driver polls for container exit in Wait()
+const waitPollInterval = 100 * time.Millisecond
+
+// stopSignalName normalizes a configured stop signal name (e.g. "sigterm",
+// "SIGKILL", "TERM") to the bare uppercase form OCI runtime CLIs expect,
+// falling back to the provided default when unset
+func stopSignalName(signal, fallback string) string {
+	if signal == "" {
+		return fallback
+	}
+	return strings.TrimPrefix(strings.ToUpper(signal), "SIG")
+}
+
+// criuImagePath returns the checkpoint image directory used for a named
+// container by the checkpoint/restore benchmark commands
+func criuImagePath(name string) string {
+	return "/tmp/bb-criu-" + name
+}
+
+// cloneBundle copies an OCI bundle directory (config.json and rootfs)
+// to dst; used by the per-thread bundle isolation mode of the
+// OCI-binary drivers
+func cloneBundle(ctx context.Context, src, dst string) error {
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return fmt.Errorf("failed to create bundle copy dir %q: %v", dst, err)
+	}
+	if out, err := utils.ExecCmd(ctx, "cp", "-a "+src+"/. "+dst); err != nil {
+		return fmt.Errorf("failed to copy bundle %q to %q: (err: %v) output: %s", src, dst, err, out)
+	}
+	return nil
+}
+
+// bundleKey maps a benchmark container name ("bb-<thread>-<iteration>")
+// to a per-thread key by dropping the iteration suffix, so each thread
+// gets one isolated bundle copy reused across its iterations
+func bundleKey(name string) string {
+	parts := strings.Split(name, "-")
+	if len(parts) >= 3 {
+		return strings.Join(parts[:len(parts)-1], "-")
+	}
+	return name
+}
+
+// runtimeGlobalOpts builds the global option prefix (trailing space
+// included when non-empty) that the OCI-binary drivers insert before
+// every runtime subcommand, e.g. "--systemd-cgroup --root /run/runc "
+func runtimeGlobalOpts(config *Config) string {
+	var opts string
+	if config.SystemdCgroup {
+		opts += "--systemd-cgroup "
+	}
+	if config.RuntimeRoot != "" {
+		opts += "--root " + config.RuntimeRoot + " "
+	}
+	return opts
+}
+
+// Capabilities describes which optional operations a driver
+// implementation supports; commands a driver cannot perform are skipped
+// by the benchmark runner with a warning rather than recorded as
+// zero-duration no-ops or guaranteed errors
+type Capabilities struct {
+	// Pause covers the pause/unpause commands
+	Pause bool
+	// Exec covers running a command inside a benchmark container
+	Exec bool
+	// Wait covers blocking until a container exits
+	Wait bool
+	// Logs covers retrieving container log output
+	Logs bool
+	// Metrics covers the stats/metrics streaming command
+	Metrics bool
+	// Checkpoint covers the CRIU checkpoint/restore commands
+	Checkpoint bool
+}
+
+// LeakAuditor is implemented by drivers which can enumerate leftover
+// benchmark resources after Clean, so the post-run leak audit can report
+// when an engine failed to tear down under churn
+type LeakAuditor interface {
+	// AuditLeaks returns one description per bb-labelled resource
+	// (container, pod, snapshot) still present
+	AuditLeaks(ctx context.Context) ([]string, error)
+}
+
+// ImageRemover is implemented by drivers which can remove an image from
+// their local store; used by the cold-cache mode to force a pull on every
+// iteration
+type ImageRemover interface {
+	// RemoveImage removes the named image from the driver's image store
+	RemoveImage(ctx context.Context, image string) error
+}
+
+// SnapshotBenchmarker is implemented by drivers which can benchmark
+// snapshotter operations (prepare, commit, diff export) directly, isolating
+// storage-driver performance from full container lifecycle timing
+type SnapshotBenchmarker interface {
+	// SnapshotPrepare creates an active snapshot on top of the container
+	// image's layer chain
+	SnapshotPrepare(ctx context.Context, ctr Container) (string, time.Duration, error)
+
+	// SnapshotCommit commits the previously prepared active snapshot
+	SnapshotCommit(ctx context.Context, ctr Container) (string, time.Duration, error)
+
+	// SnapshotDiff computes and exports the layer diff of the committed
+	// snapshot against its parent
+	SnapshotDiff(ctx context.Context, ctr Container) (string, time.Duration, error)
+}
+
+// VolumeManager is implemented by drivers whose engine manages named
+// volumes; used by the createVolume/removeVolume benchmark commands to
+// measure storage attach overhead
+type VolumeManager interface {
+	// CreateVolume creates a named volume in the engine's volume store
+	CreateVolume(ctx context.Context, name string) (string, time.Duration, error)
+
+	// RemoveVolume removes a named volume from the engine's volume store
+	RemoveVolume(ctx context.Context, name string) (string, time.Duration, error)
+}
+
+// ContainerMetricser is implemented by drivers which can take a one-shot
+// CPU/memory sample of a single benchmark container; used by the optional
+// per-container metrics collector to report container-side resource usage
+// alongside daemon overhead numbers
+type ContainerMetricser interface {
+	// ContainerMetrics returns a point-in-time sample of the container's
+	// memory usage in bytes and cumulative CPU time in nanoseconds
+	ContainerMetrics(ctx context.Context, ctr Container) (memBytes, cpuNanos uint64, err error)
+}
+
+// ImageDigester is implemented by drivers which record the resolved content
+// digest of the benchmark image after pulling (or finding) it, so results
+// capture exactly which image variant was measured
+type ImageDigester interface {
+	// ImageDigest returns the resolved digest of the benchmark image, or
+	// an empty string when no image has been resolved yet
+	ImageDigest() string
+}
+
+// OperationTimings is implemented by container types whose driver records
+// fine-grained per-operation durations beyond the single duration returned
+// from each driver call (e.g. pod sandbox vs container steps for CRI)
+type OperationTimings interface {
+	// Timings returns and clears the durations recorded since the last call
+	Timings() map[string]time.Duration
 }
 
 // Config represents various configuration flags for driver
+// defaultCFSPeriod is the CFS scheduling period (microseconds) paired with
+// a configured CPU quota
+const defaultCFSPeriod = 100000
+
+// image pull policies accepted by the drivers which pre-pull in Create
+const (
+	// PullAlways re-pulls the benchmark image on every create, so each
+	// iteration measures a fresh pull
+	PullAlways = "always"
+	// PullIfNotPresent pulls only when the image is missing from the
+	// driver's local store (the default)
+	PullIfNotPresent = "if-not-present"
+	// PullNever fails the create when the image is missing, for air-gapped
+	// hosts where an accidental pull would invalidate the run
+	PullNever = "never"
+)
+
+// ociCapabilities normalizes capability names to the upper-case CAP_-prefixed
+// form the OCI runtime spec requires, accepting either "NET_ADMIN" or
+// "CAP_NET_ADMIN" in the benchmark YAML
+func ociCapabilities(names []string) []string {
+	caps := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToUpper(name)
+		if !strings.HasPrefix(name, "CAP_") {
+			name = "CAP_" + name
+		}
+		caps = append(caps, name)
+	}
+	return caps
+}
+
+// Mount describes one host path bound into benchmark containers
+type Mount struct {
+	Source      string
+	Destination string
+	ReadOnly    bool
+}
+
+// Resources describes per-container resource limits applied to benchmark
+// containers; zero values leave the corresponding limit unset
+type Resources struct {
+	// CPUShares is the relative CPU weight versus other containers
+	CPUShares int64
+	// CPUQuota is the CFS quota in microseconds per 100ms period
+	CPUQuota int64
+	// MemoryBytes is the memory limit in bytes
+	MemoryBytes int64
+}
+
 type Config struct {
 	DriverType    Type
 	Path          string
@@ -122,17 +367,116 @@ type Config struct {
 	LogOpts       map[string]string
 	StreamStats   bool
 	StatsInterval time.Duration
+	// PodBench enables the CRI pod-level lifecycle benchmark mode where
+	// sandbox and container operations are timed as distinct stat keys
+	PodBench bool
+	// ColdCache removes the benchmark image between iterations so every
+	// run measures cold-start behavior
+	ColdCache bool
+	// Network names a real (bridge/CNI) network to attach benchmark
+	// containers to; when set, drivers which can decompose the attach will
+	// report network setup time as a distinct stat key
+	Network string
+	// Volumes lists bind mounts and named volumes ("source:dest" form) to
+	// attach to benchmark containers; named volume sources are only
+	// supported by the Docker drivers
+	Volumes []string
+	// Mounts lists structured bind mounts to attach to benchmark
+	// containers; unlike Volumes these carry a read-only flag and are
+	// supported by the Docker, containerd, and CRI drivers
+	Mounts []Mount
+	// Resources optionally constrains benchmark containers so runtimes can
+	// be compared under (and on the overhead of) limit enforcement
+	Resources *Resources
+	// Privileged runs benchmark containers in privileged mode, needed for
+	// workloads like DinD or device-heavy containers
+	Privileged bool
+	// CapAdd/CapDrop adjust the capability set of benchmark containers;
+	// names are accepted with or without the CAP_ prefix
+	CapAdd  []string
+	CapDrop []string
+	// AutoRemove makes the Docker drivers remove benchmark containers
+	// automatically on exit (docker run --rm)
+	AutoRemove bool
+	// RestartPolicy sets the Docker restart policy (e.g. "no",
+	// "on-failure:2", "unless-stopped") for benchmark containers
+	RestartPolicy string
+	// Runtime selects an alternative OCI runtime registered with the
+	// Docker daemon (e.g. "kata", "runsc", "youki")
+	Runtime string
+	// WaitHealthy makes the Docker API driver time `run` until the
+	// container's HEALTHCHECK reports healthy, for an apples-to-apples
+	// "ready" metric on daemon workloads
+	WaitHealthy bool
+	// DockerHost points the Docker API driver at a remote daemon URL
+	// (e.g. "tcp://10.0.0.5:2376"); the DOCKER_HOST environment default
+	// applies when unset
+	DockerHost string
+	// DockerTLSCertPath names a directory holding ca.pem/cert.pem/key.pem
+	// used for TLS connections to a remote Docker daemon
+	DockerTLSCertPath string
+	// ContainerdTLSCertPath names a directory holding
+	// ca.pem/cert.pem/key.pem used when the containerd endpoint (Path) is
+	// a tcp:// URL to a remote daemon
+	ContainerdTLSCertPath string
+	// EventTimings subscribes to the engine's event stream and records the
+	// gap between API returns and the matching asynchronous events as
+	// extra stat keys (containerd and Docker API drivers)
+	EventTimings bool
+	// RegistryAuth supplies credentials for image pulls from private
+	// registries (Docker API, containerd, and CRI drivers)
+	RegistryAuth *RegistryAuth
+	// Platform pins image pulls to a platform (e.g. "linux/arm64") so
+	// multi-arch references resolve deterministically; the daemon default
+	// applies when unset
+	Platform string
+	// PullPolicy controls whether drivers pre-pull the benchmark image in
+	// Create (PullAlways, PullIfNotPresent, PullNever); PullIfNotPresent
+	// applies when unset
+	PullPolicy string
+	// StopSignal is the signal ("SIGTERM", "KILL", ...) sent to containers
+	// by the stop command; each driver applies its own default when unset
+	StopSignal string
+	// StopTimeout is the grace period allowed for a container to exit after
+	// the stop signal before it is forcibly killed
+	StopTimeout time.Duration
+	// ContainerdNamespace is the containerd namespace used for benchmark
+	// containers; the driver default ("bb") applies when unset
+	ContainerdNamespace string
+	// SandboxConfigPath and ContainerConfigPath point the CRI driver at
+	// custom pod sandbox/container config JSON templates; built-in defaults
+	// apply when unset and the contrib/ files aren't present
+	SandboxConfigPath   string
+	ContainerConfigPath string
+	// SandboxReuse makes the CRI driver keep one pod sandbox per thread and
+	// churn containers inside it (kubelet-like), instead of the default
+	// one-pod-per-container behavior
+	SandboxReuse bool
+	// PauseImage overrides the pod sandbox (pause) image used by the CRI
+	// driver, for air-gapped clusters and mirrored registries
+	PauseImage string
+	// SystemdCgroup passes --systemd-cgroup to the OCI-binary drivers
+	// (runc/crun/youki) so the runtime uses the systemd cgroup manager,
+	// matching how kubelet/containerd typically invoke it
+	SystemdCgroup bool
+	// RuntimeRoot passes --root to the OCI-binary drivers to use a custom
+	// runtime state directory instead of the runtime's default
+	RuntimeRoot string
+	// BundleIsolation makes the OCI-binary drivers clone the bundle
+	// (config.json and rootfs) once per benchmark thread, so concurrent
+	// containers don't share one mutable rootfs
+	BundleIsolation bool
 }
 
 // New creates a driver instance of a specific type
 func New(ctx context.Context, config *Config) (Driver, error) {
 	switch config.DriverType {
 	case Runc:
-		return NewRuncDriver(config.Path)
+		return NewRuncDriver(config)
 	case CRun:
-		return NewCRunDriver(config.Path)
+		return NewCRunDriver(config)
 	case Youki:
-		return NewYoukiDriver(config.Path)
+		return NewYoukiDriver(config)
 	case DockerCLI:
 		return NewDockerCLIDriver(ctx, config)
 	case Docker:
@@ -142,7 +486,7 @@ func New(ctx context.Context, config *Config) (Driver, error) {
 	case Ctr:
 		return NewCtrDriver(config.Path)
 	case CRI:
-		return NewCRIDriver(config.Path)
+		return NewCRIDriver(config)
 	case Null:
 		return nil, nil
 	default:
@@ -3,26 +3,44 @@ package driver
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	cgroupsv1 "github.com/containerd/cgroups/stats/v1"
 	"github.com/containerd/containerd"
+	apievents "github.com/containerd/containerd/api/events"
 	"github.com/containerd/containerd/cio"
 	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/images"
 	"github.com/containerd/containerd/namespaces"
 	"github.com/containerd/containerd/oci"
+	remotedocker "github.com/containerd/containerd/remotes/docker"
+	"github.com/containerd/containerd/rootfs"
+	"github.com/containerd/containerd/snapshots"
+	typeurl "github.com/containerd/typeurl/v2"
 	"github.com/estesp/bucketbench/utils"
+	"github.com/opencontainers/image-spec/identity"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	grpcinsecure "google.golang.org/grpc/credentials/insecure"
 )
 
 const (
 	defaultContainerdPath = "/run/containerd/containerd.sock"
 	containerdDaemonName  = "containerd"
-	containerdNamespace   = "bb"
+	containerdNamespace   = "bb" // default; overridable via driver config
 )
 
 var containerdProcNames = []string{
@@ -30,25 +48,90 @@ var containerdProcNames = []string{
 	"containerd-shim",
 }
 
+// signals supported for the configurable container stop signal
+var stopSignals = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"TERM": syscall.SIGTERM,
+}
+
+// stopSyscallSignal maps the configured stop signal name to a syscall-level
+// signal, defaulting to SIGKILL for unset or unrecognized names
+func stopSyscallSignal(name string) syscall.Signal {
+	if sig, ok := stopSignals[stopSignalName(name, "KILL")]; ok {
+		return sig
+	}
+	log.Warnf("unrecognized stop signal %q; using SIGKILL", name)
+	return syscall.SIGKILL
+}
+
 // ContainerdDriver is an implementation of the driver interface for using Containerd.
 // This uses the provided client library which abstracts using the gRPC APIs directly.
-// IMPORTANT: This implementation does not protect instance metadata for thread safely.
-// At this time there is no understood use case for multi-threaded use of this implementation.
+// The driver is safe for concurrent use: its fields are read-only after
+// construction, per-container state lives on the Container objects, and the
+// underlying gRPC client multiplexes calls from multiple goroutines.
 type ContainerdDriver struct {
 	ctrdAddress   string
 	client        *containerd.Client
+	namespace     string
 	streamStats   bool
 	statsInterval time.Duration
+	volumes       []string
+	mounts        []Mount
+	resources     *Resources
+	privileged    bool
+	capAdd        []string
+	capDrop       []string
+	registryAuth  *RegistryAuth
+	platform      string
+	pullPolicy    string
+	stopSignal    syscall.Signal
+	stopTimeout   time.Duration
+
+	digestMu    sync.Mutex
+	imageDigest string
+
+	// events correlates task API returns with the asynchronous
+	// TaskStart/TaskExit events when event timings are enabled; nil otherwise
+	events *eventCorrelator
 }
 
 // ContainerdContainer is an implementation of the container metadata needed for containerd
 type ContainerdContainer struct {
 	name        string
 	imageName   string
-	cmdOverride string
+	cmdOverride []string
 	state       string
 	process     string
 	trace       bool
+	logs        *bytes.Buffer
+	// timingMu guards timings: the event monitor goroutine records event
+	// latencies concurrently with the benchmark thread's own timings
+	timingMu sync.Mutex
+	timings  map[string]time.Duration
+}
+
+// recordTiming stores a fine-grained operation duration for later retrieval
+// via the OperationTimings interface
+func (c *ContainerdContainer) recordTiming(name string, elapsed time.Duration) {
+	c.timingMu.Lock()
+	defer c.timingMu.Unlock()
+	if c.timings == nil {
+		c.timings = make(map[string]time.Duration)
+	}
+	c.timings[name] = elapsed
+}
+
+// Timings returns and clears the fine-grained operation durations recorded
+// since the last call
+func (c *ContainerdContainer) Timings() map[string]time.Duration {
+	c.timingMu.Lock()
+	defer c.timingMu.Unlock()
+	timings := c.timings
+	c.timings = nil
+	return timings
 }
 
 // NewContainerdDriver creates an instance of the containerd driver, providing a path to the ctr client
@@ -58,24 +141,119 @@ func NewContainerdDriver(config *Config) (*ContainerdDriver, error) {
 		path = defaultContainerdPath
 	}
 
-	client, err := containerd.New(path)
+	client, err := newContainerdClient(path, config.ContainerdTLSCertPath)
 	if err != nil {
 		return &ContainerdDriver{}, err
 	}
 
+	namespace := config.ContainerdNamespace
+	if namespace == "" {
+		namespace = containerdNamespace
+	}
+
 	driver := &ContainerdDriver{
 		ctrdAddress:   path,
 		client:        client,
+		namespace:     namespace,
 		streamStats:   config.StreamStats,
 		statsInterval: config.StatsInterval,
+		volumes:       config.Volumes,
+		mounts:        config.Mounts,
+		resources:     config.Resources,
+		privileged:    config.Privileged,
+		capAdd:        config.CapAdd,
+		capDrop:       config.CapDrop,
+		registryAuth:  config.RegistryAuth,
+		platform:      config.Platform,
+		pullPolicy:    config.PullPolicy,
+		stopSignal:    stopSyscallSignal(config.StopSignal),
+		stopTimeout:   config.StopTimeout,
+	}
+
+	if config.EventTimings {
+		driver.events = newEventCorrelator()
+		go driver.monitorEvents(namespaces.WithNamespace(context.Background(), namespace))
 	}
 
 	return driver, nil
 }
 
+// monitorEvents subscribes to the containerd task event stream and resolves
+// the gap between each task API return and the corresponding asynchronous
+// TaskStart/TaskExit event for benchmark containers
+func (r *ContainerdDriver) monitorEvents(ctx context.Context) {
+	ch, errs := r.client.Subscribe(ctx, `topic=="/tasks/start"`, `topic=="/tasks/exit"`)
+	for {
+		select {
+		case envelope, ok := <-ch:
+			if !ok {
+				return
+			}
+			event, err := typeurl.UnmarshalAny(envelope.Event)
+			if err != nil {
+				log.Debugf("event timings: can't unmarshal %s event: %v", envelope.Topic, err)
+				continue
+			}
+			switch e := event.(type) {
+			case *apievents.TaskStart:
+				if strings.HasPrefix(e.ContainerID, ContainerNamePrefix) {
+					r.events.observe(e.ContainerID, "taskStartEvent", envelope.Timestamp)
+				}
+			case *apievents.TaskExit:
+				if strings.HasPrefix(e.ContainerID, ContainerNamePrefix) {
+					r.events.observe(e.ContainerID, "taskExitEvent", envelope.Timestamp)
+				}
+			}
+		case err := <-errs:
+			if err != nil {
+				log.Debugf("event timings: subscription ended: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// newContainerdClient connects to the configured containerd endpoint.
+// Plain paths and unix:// URLs dial the local socket as before, while
+// tcp:// URLs (with optional TLS certs) reach a remote containerd; npipe
+// paths on Windows pass through to the client's platform dialer.
+func newContainerdClient(endpoint, tlsCertPath string) (*containerd.Client, error) {
+	switch {
+	case strings.HasPrefix(endpoint, "unix://"):
+		return containerd.New(strings.TrimPrefix(endpoint, "unix://"))
+	case strings.HasPrefix(endpoint, "tcp://"):
+		creds := grpcinsecure.NewCredentials()
+		if tlsCertPath != "" {
+			cert, err := tls.LoadX509KeyPair(filepath.Join(tlsCertPath, "cert.pem"), filepath.Join(tlsCertPath, "key.pem"))
+			if err != nil {
+				return nil, errors.Wrap(err, "failed loading containerd TLS client keypair")
+			}
+			caData, err := os.ReadFile(filepath.Join(tlsCertPath, "ca.pem"))
+			if err != nil {
+				return nil, errors.Wrap(err, "failed reading containerd TLS CA")
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(caData) {
+				return nil, errors.New("no valid certificates in containerd TLS CA file")
+			}
+			creds = credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{cert}, RootCAs: pool})
+		}
+		dialCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		conn, err := grpc.DialContext(dialCtx, strings.TrimPrefix(endpoint, "tcp://"),
+			grpc.WithBlock(), grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to dial containerd at '%s'", endpoint)
+		}
+		return containerd.NewWithConn(conn)
+	default:
+		return containerd.New(endpoint)
+	}
+}
+
 // newContainerdContainer creates the metadata object of a containerd-specific container with
 // bundle, name, and any required additional information
-func newContainerdContainer(name, image, cmd string, trace bool) Container {
+func newContainerdContainer(name, image string, cmd []string, trace bool) Container {
 	return &ContainerdContainer{
 		name:        name,
 		imageName:   image,
@@ -101,7 +279,7 @@ func (c *ContainerdContainer) Image() string {
 
 // Command returns the override command that will be executed instead of
 // the default image-specified command
-func (c *ContainerdContainer) Command() string {
+func (c *ContainerdContainer) Command() []string {
 	return c.cmdOverride
 }
 
@@ -127,6 +305,12 @@ func (c *ContainerdContainer) GetPodID() string {
 	return ""
 }
 
+// Capabilities reports the optional operations this driver
+// supports: containerd supports everything except CRIU checkpoint/restore
+func (r *ContainerdDriver) Capabilities() Capabilities {
+	return Capabilities{Pause: true, Exec: true, Wait: true, Logs: true, Metrics: true, Checkpoint: false}
+}
+
 // Type returns a driver.Type to indentify the driver implementation
 func (r *ContainerdDriver) Type() Type {
 	return Containerd
@@ -151,7 +335,7 @@ func (r *ContainerdDriver) PID() (int, error) {
 // Wait blocks thread until container stop
 func (r *ContainerdDriver) Wait(ctx context.Context, ctr Container) (string, time.Duration, error) {
 	start := time.Now()
-	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
 
 	container, err := r.client.LoadContainer(ctx, ctr.Name())
 	if err != nil {
@@ -177,10 +361,15 @@ func (r *ContainerdDriver) Wait(ctx context.Context, ctr Container) (string, tim
 		return "", 0, err
 	}
 
-	<-statusC
+	exitStatus := <-statusC
 
 	elapsed := time.Since(start)
-	return "", elapsed, nil
+	code, _, err := exitStatus.Result()
+	if err != nil {
+		return "", elapsed, err
+	}
+	// report the exit code as output so expectations can assert on it
+	return strconv.FormatUint(uint64(code), 10), elapsed, nil
 }
 
 // ProcNames returns the list of process names contributing to mem/cpu usage during overhead benchmark
@@ -188,9 +377,48 @@ func (r *ContainerdDriver) ProcNames() []string {
 	return containerdProcNames
 }
 
+// ContainerMetrics samples the container task's cgroup metrics, returning
+// current memory usage and cumulative CPU time
+func (r *ContainerdDriver) ContainerMetrics(ctx context.Context, ctr Container) (uint64, uint64, error) {
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
+
+	container, err := r.client.LoadContainer(ctx, ctr.Name())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	metric, err := task.Metrics(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	data, err := typeurl.UnmarshalAny(metric.Data)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	m, ok := data.(*cgroupsv1.Metrics)
+	if !ok {
+		return 0, 0, fmt.Errorf("unsupported metrics type %T", data)
+	}
+	return m.Memory.Usage.Usage, m.CPU.Usage.Total, nil
+}
+
+// DaemonCGroupPath returns the systemd service cgroup containerd runs under;
+// sampling this hierarchy captures the daemon and its shims without relying
+// on a process-name list that can miss v2 shim binaries
+func (r *ContainerdDriver) DaemonCGroupPath() string {
+	return "/system.slice/containerd.service"
+}
+
 // Stats returns stats data from daemon for container
 func (r *ContainerdDriver) Stats(ctx context.Context, ctr Container) (io.ReadCloser, error) {
-	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
 
 	container, err := r.client.LoadContainer(ctx, ctr.Name())
 	if err != nil {
@@ -237,9 +465,20 @@ func (r *ContainerdDriver) Stats(ctx context.Context, ctr Container) (io.ReadClo
 	return reader, nil
 }
 
+// Logs returns a reader with the container's log output; containerd does
+// not persist task output, so this returns the stream captured when the
+// task was started by this driver instance
+func (r *ContainerdDriver) Logs(_ context.Context, ctr Container) (io.ReadCloser, error) {
+	containerdCtr, ok := ctr.(*ContainerdContainer)
+	if !ok || containerdCtr.logs == nil {
+		return nil, errors.New("no log stream captured for container")
+	}
+	return io.NopCloser(bytes.NewReader(containerdCtr.logs.Bytes())), nil
+}
+
 // Info returns
 func (r *ContainerdDriver) Info(ctx context.Context) (string, error) {
-	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
 
 	version, err := r.client.Version(ctx)
 	if err != nil {
@@ -251,28 +490,151 @@ func (r *ContainerdDriver) Info(ctx context.Context) (string, error) {
 
 // Create will create a container instance matching the specific needs
 // of a driver
-func (r *ContainerdDriver) Create(ctx context.Context, name, image, cmdOverride string, _ bool, trace bool) (Container, error) {
-	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
+func (r *ContainerdDriver) Create(ctx context.Context, name, image string, cmdOverride []string, _ bool, trace bool) (Container, error) {
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
 
 	// we need to convert the bare Docker image name to a fully resolved
 	// reference (since the Docker driver and containerd driver share image
 	// name references)
 	fullImageName := resolveDockerImageName(image)
-	if _, err := r.client.GetImage(ctx, fullImageName); err != nil {
-		// if the image isn't already in our namespaced context, then pull it
-		// using the reference and default resolver (most likely DockerHub)
-		if _, err := r.client.Pull(ctx, fullImageName, containerd.WithPullUnpack); err != nil {
+	img, err := r.client.GetImage(ctx, fullImageName)
+	if err != nil && r.pullPolicy == PullNever {
+		return nil, fmt.Errorf("image '%s' not present and pull policy is %q", fullImageName, PullNever)
+	}
+	var pullElapsed, unpackElapsed time.Duration
+	if err != nil || r.pullPolicy == PullAlways {
+		// if the image isn't already in our namespaced context (or the pull
+		// policy forces a fresh pull), then pull it using the reference and
+		// default resolver (most likely DockerHub)
+		pullOpts := []containerd.RemoteOpt{}
+		if r.platform != "" {
+			pullOpts = append(pullOpts, containerd.WithPlatform(r.platform))
+		}
+		username, password, err := r.registryAuth.Resolve(fullImageName)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to resolve registry credentials")
+		}
+		if username != "" {
+			authorizer := remotedocker.NewDockerAuthorizer(
+				remotedocker.WithAuthCreds(func(string) (string, string, error) {
+					return username, password, nil
+				}))
+			resolver := remotedocker.NewResolver(remotedocker.ResolverOptions{
+				Hosts: remotedocker.ConfigureDefaultRegistries(remotedocker.WithAuthorizer(authorizer)),
+			})
+			pullOpts = append(pullOpts, containerd.WithResolver(resolver))
+		}
+		pullStart := time.Now()
+		if img, err = r.client.Pull(ctx, fullImageName, pullOpts...); err != nil {
 			// error pulling the image
 			return nil, err
 		}
+		pullElapsed = time.Since(pullStart)
+		// unpack as a separate step so registry transfer and snapshot
+		// preparation are measured independently; the two regress for
+		// different reasons (network/registry vs snapshotter performance)
+		unpackStart := time.Now()
+		if err := img.Unpack(ctx, containerd.DefaultSnapshotter); err != nil {
+			return nil, errors.Wrapf(err, "failed to unpack image '%s'", fullImageName)
+		}
+		unpackElapsed = time.Since(unpackStart)
+	}
+
+	// record the digest the reference resolved to so results capture the
+	// exact image variant measured
+	r.digestMu.Lock()
+	r.imageDigest = img.Target().Digest.String()
+	r.digestMu.Unlock()
+
+	ctr := newContainerdContainer(name, fullImageName, cmdOverride, trace)
+	if pullElapsed > 0 {
+		if containerdCtr, ok := ctr.(*ContainerdContainer); ok {
+			containerdCtr.recordTiming("pull", pullElapsed)
+			containerdCtr.recordTiming("unpack", unpackElapsed)
+		}
 	}
+	return ctr, nil
+}
 
-	return newContainerdContainer(name, fullImageName, cmdOverride, trace), nil
+// ImageDigest returns the resolved digest of the benchmark image recorded
+// at create time
+func (r *ContainerdDriver) ImageDigest() string {
+	r.digestMu.Lock()
+	defer r.digestMu.Unlock()
+	return r.imageDigest
+}
+
+// RemoveImage removes the named image from containerd's image store
+func (r *ContainerdDriver) RemoveImage(ctx context.Context, image string) error {
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
+	return r.client.ImageService().Delete(ctx, resolveDockerImageName(image), images.SynchronousDelete())
+}
+
+// snapshotKey returns the snapshot key used for a named container by the
+// snapshot benchmark commands
+func snapshotKey(name string) string {
+	return "bb-snap-" + name
+}
+
+// SnapshotPrepare creates an active snapshot on top of the container image's
+// layer chain
+func (r *ContainerdDriver) SnapshotPrepare(ctx context.Context, ctr Container) (string, time.Duration, error) {
+	start := time.Now()
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
+
+	image, err := r.client.GetImage(ctx, ctr.Image())
+	if err != nil {
+		return "", 0, err
+	}
+	diffIDs, err := image.RootFS(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	parent := identity.ChainID(diffIDs).String()
+
+	sn := r.client.SnapshotService(containerd.DefaultSnapshotter)
+	if _, err := sn.Prepare(ctx, snapshotKey(ctr.Name()), parent); err != nil {
+		return "", 0, err
+	}
+
+	elapsed := time.Since(start)
+	return "", elapsed, nil
+}
+
+// SnapshotCommit commits the previously prepared active snapshot
+func (r *ContainerdDriver) SnapshotCommit(ctx context.Context, ctr Container) (string, time.Duration, error) {
+	start := time.Now()
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
+
+	key := snapshotKey(ctr.Name())
+	sn := r.client.SnapshotService(containerd.DefaultSnapshotter)
+	if err := sn.Commit(ctx, key+"-committed", key); err != nil {
+		return "", 0, err
+	}
+
+	elapsed := time.Since(start)
+	return "", elapsed, nil
+}
+
+// SnapshotDiff computes the layer diff of the committed snapshot against its
+// parent and exports it to the content store
+func (r *ContainerdDriver) SnapshotDiff(ctx context.Context, ctr Container) (string, time.Duration, error) {
+	start := time.Now()
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
+
+	sn := r.client.SnapshotService(containerd.DefaultSnapshotter)
+	desc, err := rootfs.CreateDiff(ctx, snapshotKey(ctr.Name())+"-committed", sn, r.client.DiffService())
+	if err != nil {
+		return "", 0, err
+	}
+
+	elapsed := time.Since(start)
+	return desc.Digest.String(), elapsed, nil
 }
 
 // Clean will clean the environment; removing any remaining containers in the runc metadata
 func (r *ContainerdDriver) Clean(ctx context.Context) error {
-	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
 
 	var tries int
 	list, err := r.client.Containers(ctx)
@@ -283,60 +645,142 @@ func (r *ContainerdDriver) Clean(ctx context.Context) error {
 	log.Infof("Attempting to cleanup containerd containers/metadata; %d listed", len(list))
 	for len(list) > 0 && tries < 3 {
 		log.Infof("containerd cleanup: Pass #%d", tries+1)
-		// kill/stop and remove containers
-		for _, ctr := range list {
-			if err := stopTask(ctx, ctr); err != nil {
+		// kill/stop and remove containers in parallel; a large density
+		// run can leave thousands behind
+		cleanupParallel(len(list), func(i int) {
+			ctr := list[i]
+			if err := stopTask(ctx, ctr, syscall.SIGKILL, 0); err != nil {
 				log.Errorf("Error stopping container: %v", err)
 			}
 			if err := ctr.Delete(ctx, containerd.WithSnapshotCleanup); err != nil {
 				log.Errorf("Error deleting container %v", err)
 			}
-		}
+		})
 		tries++
 		list, err = r.client.Containers(ctx)
 		if err != nil {
 			return fmt.Errorf("Error getting containerd list output: %v", err)
 		}
 	}
+
+	// remove any snapshots left behind by the snapshot benchmark commands
+	sn := r.client.SnapshotService(containerd.DefaultSnapshotter)
+	err = sn.Walk(ctx, func(ctx context.Context, info snapshots.Info) error {
+		if strings.HasPrefix(info.Name, snapshotKey("")) {
+			if err := sn.Remove(ctx, info.Name); err != nil {
+				log.Errorf("Error removing snapshot %q: %v", info.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("Error walking snapshots during cleanup: %v", err)
+	}
 	log.Infof("containerd cleanup complete.")
 	return nil
 }
 
+// AuditLeaks lists bb-labelled containers and snapshots still present in
+// the driver's namespace, for the post-run leak audit
+func (r *ContainerdDriver) AuditLeaks(ctx context.Context) ([]string, error) {
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
+	containers, err := r.client.Containers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var leaks []string
+	for _, ctr := range containers {
+		if strings.HasPrefix(ctr.ID(), ContainerNamePrefix) {
+			leaks = append(leaks, "container "+ctr.ID())
+		}
+	}
+	sn := r.client.SnapshotService(containerd.DefaultSnapshotter)
+	if err := sn.Walk(ctx, func(_ context.Context, info snapshots.Info) error {
+		if strings.HasPrefix(info.Name, ContainerNamePrefix) {
+			leaks = append(leaks, "snapshot "+info.Name)
+		}
+		return nil
+	}); err != nil {
+		log.Debugf("leak audit: snapshot walk failed: %v", err)
+	}
+	return leaks, nil
+}
+
 // Run will execute a container using the containerd driver.
 func (r *ContainerdDriver) Run(ctx context.Context, ctr Container) (string, time.Duration, error) {
 	start := time.Now()
-	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
 
 	image, err := r.client.GetImage(ctx, ctr.Image())
 	if err != nil {
 		return "", 0, err
 	}
-	var container containerd.Container
-	if ctr.Command() != "" {
+	specOpts := []oci.SpecOpts{oci.WithImageConfig(image)}
+	if len(ctr.Command()) > 0 {
 		// the command needs to be overridden in the generated spec
-		container, err = r.client.NewContainer(ctx, ctr.Name(),
-			containerd.WithNewSnapshot(ctr.Name(), image),
-			containerd.WithNewSpec(oci.WithImageConfig(image),
-				oci.WithProcessArgs(strings.Split(ctr.Command(), " ")...)))
-	} else {
-		container, err = r.client.NewContainer(ctx, ctr.Name(),
-			containerd.WithNewSnapshot(ctr.Name(), image),
-			containerd.WithNewSpec(oci.WithImageConfig(image)))
+		specOpts = append(specOpts, oci.WithProcessArgs(ctr.Command()...))
+	}
+	if len(r.volumes) > 0 {
+		mounts, err := bindMounts(r.volumes)
+		if err != nil {
+			return "", 0, err
+		}
+		specOpts = append(specOpts, oci.WithMounts(mounts))
+	}
+	if len(r.mounts) > 0 {
+		specOpts = append(specOpts, oci.WithMounts(structuredMounts(r.mounts)))
 	}
+	if r.resources != nil {
+		if r.resources.CPUShares > 0 {
+			specOpts = append(specOpts, oci.WithCPUShares(uint64(r.resources.CPUShares)))
+		}
+		if r.resources.CPUQuota > 0 {
+			specOpts = append(specOpts, oci.WithCPUCFS(r.resources.CPUQuota, defaultCFSPeriod))
+		}
+		if r.resources.MemoryBytes > 0 {
+			specOpts = append(specOpts, oci.WithMemoryLimit(uint64(r.resources.MemoryBytes)))
+		}
+	}
+	if r.privileged {
+		specOpts = append(specOpts, oci.WithPrivileged)
+	}
+	if len(r.capAdd) > 0 {
+		specOpts = append(specOpts, oci.WithAddedCapabilities(ociCapabilities(r.capAdd)))
+	}
+	if len(r.capDrop) > 0 {
+		specOpts = append(specOpts, oci.WithDroppedCapabilities(ociCapabilities(r.capDrop)))
+	}
+
+	container, err := r.client.NewContainer(ctx, ctr.Name(),
+		containerd.WithNewSnapshot(ctr.Name(), image),
+		containerd.WithNewSpec(specOpts...))
 	if err != nil {
 		return "", 0, err
 	}
+	created := time.Now()
 
 	stdouterr := bytes.NewBuffer(nil)
 	task, err := container.NewTask(ctx, cio.NewCreator(cio.WithStreams(bytes.NewBuffer(nil), stdouterr, stdouterr)))
 	if err != nil {
 		return "", 0, err
 	}
+	// retain the output stream so it can be retrieved later via Logs()
+	if containerdCtr, ok := ctr.(*ContainerdContainer); ok {
+		containerdCtr.logs = stdouterr
+	}
 	if err := task.Start(ctx); err != nil {
 		task.Delete(ctx)
 		return "", 0, err
 	}
 	elapsed := time.Since(start)
+
+	// record the create and start steps as distinct stat keys
+	if containerdCtr, ok := ctr.(*ContainerdContainer); ok {
+		containerdCtr.recordTiming("createContainer", created.Sub(start))
+		containerdCtr.recordTiming("startContainer", time.Since(created))
+		r.events.expect(containerdCtr, containerdCtr.Name(), "taskStartEvent", time.Now())
+	}
+
 	return stdouterr.String(), elapsed, nil
 }
 
@@ -344,20 +788,23 @@ func (r *ContainerdDriver) Run(ctx context.Context, ctr Container) (string, time
 // running in the container)
 func (r *ContainerdDriver) Stop(ctx context.Context, ctr Container) (string, time.Duration, error) {
 	start := time.Now()
-	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
 
 	container, err := r.client.LoadContainer(ctx, ctr.Name())
 	if err != nil {
 		return "", 0, err
 	}
 
-	if err = stopTask(ctx, container); err != nil {
+	if err = stopTask(ctx, container, r.stopSignal, r.stopTimeout); err != nil {
 		// ignore if the error is that the process had already exited:
 		if !strings.Contains(err.Error(), "not found") {
 			return "", 0, err
 		}
 	}
 	elapsed := time.Since(start)
+	if containerdCtr, ok := ctr.(*ContainerdContainer); ok {
+		r.events.expect(containerdCtr, containerdCtr.Name(), "taskExitEvent", time.Now())
+	}
 	return "", elapsed, nil
 }
 
@@ -365,14 +812,14 @@ func (r *ContainerdDriver) Stop(ctx context.Context, ctr Container) (string, tim
 // which will remove any container metadata if it was running
 func (r *ContainerdDriver) Remove(ctx context.Context, ctr Container) (string, time.Duration, error) {
 	start := time.Now()
-	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
 
 	container, err := r.client.LoadContainer(ctx, ctr.Name())
 	if err != nil {
 		return "", 0, err
 	}
 
-	if err = stopTask(ctx, container); err != nil {
+	if err = stopTask(ctx, container, syscall.SIGKILL, 0); err != nil {
 		return "", 0, err
 	}
 
@@ -385,10 +832,63 @@ func (r *ContainerdDriver) Remove(ctx context.Context, ctr Container) (string, t
 	return "", elapsed, nil
 }
 
+// Checkpoint will checkpoint a running container's state via CRIU
+func (r *ContainerdDriver) Checkpoint(_ context.Context, _ Container) (string, time.Duration, error) {
+	return "", 0, errors.New("not implemented")
+}
+
+// Restore will restore a previously checkpointed container
+func (r *ContainerdDriver) Restore(_ context.Context, _ Container) (string, time.Duration, error) {
+	return "", 0, errors.New("not implemented")
+}
+
+// Exec will execute a command inside a running container via a new task
+// process, waiting for its completion
+func (r *ContainerdDriver) Exec(ctx context.Context, ctr Container, cmd string) (string, time.Duration, error) {
+	start := time.Now()
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
+
+	container, err := r.client.LoadContainer(ctx, ctr.Name())
+	if err != nil {
+		return "", 0, err
+	}
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return "", 0, err
+	}
+
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	pspec := spec.Process
+	pspec.Args = strings.Fields(cmd)
+
+	execID := fmt.Sprintf("exec-%d", time.Now().UnixNano())
+	stdouterr := bytes.NewBuffer(nil)
+	process, err := task.Exec(ctx, execID, pspec, cio.NewCreator(cio.WithStreams(bytes.NewBuffer(nil), stdouterr, stdouterr)))
+	if err != nil {
+		return "", 0, err
+	}
+	defer process.Delete(ctx)
+
+	statusC, err := process.Wait(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	if err := process.Start(ctx); err != nil {
+		return "", 0, err
+	}
+	<-statusC
+
+	elapsed := time.Since(start)
+	return stdouterr.String(), elapsed, nil
+}
+
 // Pause will pause a container
 func (r *ContainerdDriver) Pause(ctx context.Context, ctr Container) (string, time.Duration, error) {
 	start := time.Now()
-	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
 
 	container, err := r.client.LoadContainer(ctx, ctr.Name())
 	if err != nil {
@@ -409,7 +909,7 @@ func (r *ContainerdDriver) Pause(ctx context.Context, ctr Container) (string, ti
 // Unpause will unpause/resume a container
 func (r *ContainerdDriver) Unpause(ctx context.Context, ctr Container) (string, time.Duration, error) {
 	start := time.Now()
-	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
+	ctx = namespaces.WithNamespace(ctx, r.namespace)
 
 	container, err := r.client.LoadContainer(ctx, ctr.Name())
 	if err != nil {
@@ -461,8 +961,48 @@ func resolveDockerImageName(name string) string {
 	return fmt.Sprintf("%s/%s", hostname, remoteName)
 }
 
-// common code for task stop/kill using the containerd gRPC API
-func stopTask(ctx context.Context, ctr containerd.Container) error {
+// bindMounts converts "source:dest" volume entries into OCI bind mounts;
+// named volume sources (non-absolute paths) are not supported by containerd
+func bindMounts(volumes []string) ([]specs.Mount, error) {
+	var mounts []specs.Mount
+	for _, volume := range volumes {
+		parts := strings.SplitN(volume, ":", 2)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], "/") {
+			return nil, fmt.Errorf("invalid bind mount %q; containerd requires an absolute 'source:dest' pair", volume)
+		}
+		mounts = append(mounts, specs.Mount{
+			Type:        "bind",
+			Source:      parts[0],
+			Destination: parts[1],
+			Options:     []string{"rbind", "rw"},
+		})
+	}
+	return mounts, nil
+}
+
+// structuredMounts converts structured mount entries into OCI bind mounts,
+// honoring the per-mount read-only flag
+func structuredMounts(mounts []Mount) []specs.Mount {
+	var specMounts []specs.Mount
+	for _, mount := range mounts {
+		access := "rw"
+		if mount.ReadOnly {
+			access = "ro"
+		}
+		specMounts = append(specMounts, specs.Mount{
+			Type:        "bind",
+			Source:      mount.Source,
+			Destination: mount.Destination,
+			Options:     []string{"rbind", access},
+		})
+	}
+	return specMounts
+}
+
+// common code for task stop/kill using the containerd gRPC API; a non-KILL
+// signal with a positive timeout is escalated to SIGKILL if the task has
+// not exited when the grace period expires
+func stopTask(ctx context.Context, ctr containerd.Container, signal syscall.Signal, timeout time.Duration) error {
 	task, err := ctr.Task(ctx, nil)
 	if err != nil {
 		if !errdefs.IsNotFound(err) {
@@ -487,11 +1027,25 @@ func stopTask(ctx context.Context, ctr containerd.Container) error {
 		if err != nil {
 			log.Errorf("container %q: error during wait: %v", ctr.ID(), err)
 		}
-		if err := task.Kill(ctx, syscall.SIGKILL); err != nil {
+		if err := task.Kill(ctx, signal); err != nil {
 			task.Delete(ctx)
 			return err
 		}
-		status := <-statusC
+		var status containerd.ExitStatus
+		if timeout > 0 && signal != syscall.SIGKILL {
+			select {
+			case status = <-statusC:
+			case <-time.After(timeout):
+				log.Warnf("container %q did not exit within %v of signal %v; killing", ctr.ID(), timeout, signal)
+				if err := task.Kill(ctx, syscall.SIGKILL); err != nil {
+					task.Delete(ctx)
+					return err
+				}
+				status = <-statusC
+			}
+		} else {
+			status = <-statusC
+		}
 		code, _, err := status.Result()
 		if err != nil {
 			log.Errorf("container %q: error getting task result code: %v", ctr.ID(), err)
@@ -74,8 +74,8 @@ func (c *CtrContainer) Image() string {
 
 // Command is not implemented for the legacy `ctr` driver type
 // as the command is embedded in the config.json of the rootfs
-func (c *CtrContainer) Command() string {
-	return ""
+func (c *CtrContainer) Command() []string {
+	return nil
 }
 
 // Process returns the process name in cases where this container instance is
@@ -100,6 +100,13 @@ func (c *CtrContainer) GetPodID() string {
 	return ""
 }
 
+// Capabilities reports the optional operations this driver
+// supports: the legacy `ctr` tool has no log capture, stats, or
+// checkpoint/restore support
+func (r *CtrDriver) Capabilities() Capabilities {
+	return Capabilities{Pause: true, Exec: true, Wait: true, Logs: false, Metrics: false, Checkpoint: false}
+}
+
 // Type returns a driver.Type to indentify the driver implementation
 func (r *CtrDriver) Type() Type {
 	return Ctr
@@ -121,9 +128,30 @@ func (r *CtrDriver) PID() (int, error) {
 	return 0, errors.New("not implemented")
 }
 
-// Wait blocks thread until container stop
-func (r *CtrDriver) Wait(_ context.Context, _ Container) (string, time.Duration, error) {
-	return "", 0, errors.New("not implemented")
+// Wait blocks until the container no longer shows as running in the ctr
+// container list, polling until it reports stopped or disappears
+func (r *CtrDriver) Wait(ctx context.Context, ctr Container) (string, time.Duration, error) {
+	start := time.Now()
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", 0, err
+		}
+		out, err := utils.ExecCmd(ctx, r.ctrBinary, "containers")
+		if err != nil {
+			return out, 0, fmt.Errorf("Error getting containerd list output: %v", err)
+		}
+		running := false
+		for _, listed := range parseContainerdList(out) {
+			if listed.Name() == ctr.Name() && listed.State() == "running" {
+				running = true
+				break
+			}
+		}
+		if !running {
+			return "", time.Since(start), nil
+		}
+		time.Sleep(waitPollInterval)
+	}
 }
 
 // Stats returns stats data from daemon for container
@@ -131,6 +159,12 @@ func (r *CtrDriver) Stats(_ context.Context, _ Container) (io.ReadCloser, error)
 	return nil, errors.New("not implemented")
 }
 
+// Logs returns log output for container; the legacy ctr client has no
+// log retrieval support
+func (r *CtrDriver) Logs(_ context.Context, _ Container) (io.ReadCloser, error) {
+	return nil, errors.New("not implemented")
+}
+
 // ProcNames returns the list of process names contributing to mem/cpu usage during overhead benchmark
 func (r *CtrDriver) ProcNames() []string {
 	return containerdProcNames
@@ -154,7 +188,7 @@ func (r *CtrDriver) Info(ctx context.Context) (string, error) {
 
 // Create will create a container instance matching the specific needs
 // of a driver
-func (r *CtrDriver) Create(_ context.Context, name, image, _ string, _ bool, trace bool) (Container, error) {
+func (r *CtrDriver) Create(_ context.Context, name, image string, _ []string, _ bool, trace bool) (Container, error) {
 	return newCtrContainer(name, image, trace), nil
 }
 
@@ -216,6 +250,21 @@ func (r *CtrDriver) Remove(ctx context.Context, ctr Container) (string, time.Dur
 	return utils.ExecTimedCmd(ctx, r.ctrBinary, "containers kill "+ctr.Name())
 }
 
+// Exec will execute a command inside a running container
+func (r *CtrDriver) Exec(ctx context.Context, ctr Container, cmd string) (string, time.Duration, error) {
+	return utils.ExecTimedCmd(ctx, r.ctrBinary, "containers exec --id "+ctr.Name()+" "+cmd)
+}
+
+// Checkpoint will checkpoint a running container's state via CRIU
+func (r *CtrDriver) Checkpoint(_ context.Context, _ Container) (string, time.Duration, error) {
+	return "", 0, errors.New("not implemented")
+}
+
+// Restore will restore a previously checkpointed container
+func (r *CtrDriver) Restore(_ context.Context, _ Container) (string, time.Duration, error) {
+	return "", 0, errors.New("not implemented")
+}
+
 // Pause will pause a container
 func (r *CtrDriver) Pause(ctx context.Context, ctr Container) (string, time.Duration, error) {
 	return utils.ExecTimedCmd(ctx, r.ctrBinary, "containers pause "+ctr.Name())
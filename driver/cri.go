@@ -8,6 +8,8 @@ import (
 	"io"
 	"net"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -16,7 +18,7 @@ import (
 )
 
 const (
-	defaultPodImage        = "registry.k8s.io/pause:3.5"
+	defaultPodImage        = "registry.k8s.io/pause:3.9"
 	defaultPodNamePrefix   = "pod"
 	defaultSandboxConfig   = "contrib/sandbox_config.json"
 	defaultContainerConfig = "contrib/container_config.json"
@@ -35,21 +37,57 @@ type CRIDriver struct {
 	imageClient      *pb.ImageServiceClient
 	pconfig          pb.PodSandboxConfig
 	cconfig          pb.ContainerConfig
+	podBench         bool
+	networkBench     bool
+	volumes          []string
+	mounts           []Mount
+	resources        *Resources
+	privileged       bool
+	capAdd           []string
+	capDrop          []string
+	registryAuth     *RegistryAuth
+	pullPolicy       string
+	sandboxReuse     bool
+	pauseImage       string
+	sandboxMu        sync.Mutex
+	sandboxes        map[string]string
+	stopTimeout      time.Duration
+	streamStats      bool
+	statsInterval    time.Duration
 }
 
 // CRIContainer is an implementation of the container metadata needed for CRI implementation
 type CRIContainer struct {
 	name        string
 	imageName   string
-	cmdOverride string
+	cmdOverride []string
 	state       string //nolint:structcheck,unused
 	process     string //nolint:structcheck,unused
 	trace       bool
 	podID       string
+	timings     map[string]time.Duration
+}
+
+// recordTiming stores a fine-grained operation duration for later retrieval
+// via the OperationTimings interface
+func (ctr *CRIContainer) recordTiming(name string, elapsed time.Duration) {
+	if ctr.timings == nil {
+		ctr.timings = make(map[string]time.Duration)
+	}
+	ctr.timings[name] = elapsed
+}
+
+// Timings returns and clears the fine-grained operation durations recorded
+// since the last call
+func (ctr *CRIContainer) Timings() map[string]time.Duration {
+	timings := ctr.timings
+	ctr.timings = nil
+	return timings
 }
 
 // NewCRIDriver creates an instance of the CRI driver
-func NewCRIDriver(path string) (Driver, error) {
+func NewCRIDriver(config *Config) (Driver, error) {
+	path := config.Path
 	if path == "" {
 		return nil, fmt.Errorf("socket path unspecified")
 	}
@@ -62,12 +100,12 @@ func NewCRIDriver(path string) (Driver, error) {
 	runtimeClient := pb.NewRuntimeServiceClient(conn)
 	imageClient := pb.NewImageServiceClient(conn)
 
-	pconfig, err := loadPodSandboxConfig(defaultSandboxConfig)
+	pconfig, err := resolvePodSandboxConfig(config.SandboxConfigPath)
 	if err != nil {
 		return nil, err
 	}
 
-	cconfig, err := loadContainerConfig(defaultContainerConfig)
+	cconfig, err := resolveContainerConfig(config.ContainerConfigPath)
 	if err != nil {
 		return nil, err
 	}
@@ -78,6 +116,25 @@ func NewCRIDriver(path string) (Driver, error) {
 		imageClient:      &imageClient,
 		cconfig:          cconfig,
 		pconfig:          pconfig,
+		podBench:         config.PodBench,
+		networkBench:     config.Network != "",
+		volumes:          config.Volumes,
+		mounts:           config.Mounts,
+		resources:        config.Resources,
+		privileged:       config.Privileged,
+		capAdd:           config.CapAdd,
+		capDrop:          config.CapDrop,
+		registryAuth:     config.RegistryAuth,
+		pullPolicy:       config.PullPolicy,
+		sandboxReuse:     config.SandboxReuse,
+		pauseImage:       config.PauseImage,
+		sandboxes:        make(map[string]string),
+		stopTimeout:      config.StopTimeout,
+		streamStats:      config.StreamStats,
+		statsInterval:    config.StatsInterval,
+	}
+	if driver.pauseImage == "" {
+		driver.pauseImage = defaultPodImage
 	}
 
 	return driver, nil
@@ -123,7 +180,7 @@ func (ctr *CRIContainer) Image() string {
 
 // Command returns an optional command that overrides the default image
 // "CMD" or "ENTRYPOINT" for the Docker and Containerd (gRPC) drivers
-func (ctr *CRIContainer) Command() string {
+func (ctr *CRIContainer) Command() []string {
 	return ctr.cmdOverride
 }
 
@@ -132,6 +189,13 @@ func (ctr *CRIContainer) GetPodID() string {
 	return ctr.podID
 }
 
+// Capabilities reports the optional operations this driver
+// supports: the CRI API has no pause, wait, or checkpoint/restore
+// operations
+func (c *CRIDriver) Capabilities() Capabilities {
+	return Capabilities{Pause: false, Exec: true, Wait: false, Logs: true, Metrics: true, Checkpoint: false}
+}
+
 // Type returns a driver type to identify the driver
 func (c *CRIDriver) Type() Type {
 	return CRI
@@ -156,31 +220,71 @@ func (c *CRIDriver) Path() string {
 
 // Create will create a container instance matching the specific needs
 // of a driver
-func (c *CRIDriver) Create(ctx context.Context, name, image, cmdOverride string, _ bool, trace bool) (Container, error) {
-	if status, err := (*c.imageClient).ImageStatus(ctx, &pb.ImageStatusRequest{Image: &pb.ImageSpec{Image: image}}); err != nil || status.Image == nil {
-		if _, err := (*c.imageClient).PullImage(ctx, &pb.PullImageRequest{Image: &pb.ImageSpec{Image: image}}); err != nil {
+func (c *CRIDriver) Create(ctx context.Context, name, image string, cmdOverride []string, _ bool, trace bool) (Container, error) {
+	username, password, err := c.registryAuth.Resolve(image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve registry credentials: %v", err)
+	}
+	var auth *pb.AuthConfig
+	if username != "" {
+		auth = &pb.AuthConfig{Username: username, Password: password}
+	}
+
+	status, err := (*c.imageClient).ImageStatus(ctx, &pb.ImageStatusRequest{Image: &pb.ImageSpec{Image: image}})
+	present := err == nil && status.Image != nil
+	if !present && c.pullPolicy == PullNever {
+		return nil, fmt.Errorf("image '%s' not present and pull policy is %q", image, PullNever)
+	}
+	if !present || c.pullPolicy == PullAlways {
+		if _, err := (*c.imageClient).PullImage(ctx, &pb.PullImageRequest{Image: &pb.ImageSpec{Image: image}, Auth: auth}); err != nil {
 			return nil, err
 		}
 	}
 
-	if status, err := (*c.imageClient).ImageStatus(ctx, &pb.ImageStatusRequest{Image: &pb.ImageSpec{Image: defaultPodImage}}); err != nil || status.Image == nil {
-		if _, err := (*c.imageClient).PullImage(ctx, &pb.PullImageRequest{Image: &pb.ImageSpec{Image: defaultPodImage}}); err != nil {
+	if status, err := (*c.imageClient).ImageStatus(ctx, &pb.ImageStatusRequest{Image: &pb.ImageSpec{Image: c.pauseImage}}); err != nil || status.Image == nil {
+		if _, err := (*c.imageClient).PullImage(ctx, &pb.PullImageRequest{Image: &pb.ImageSpec{Image: c.pauseImage}}); err != nil {
 			return nil, err
 		}
 	}
 
+	podName := name
+	if c.sandboxReuse {
+		// in sandbox-reuse mode all of a thread's containers share one pod,
+		// so the pod identity drops the per-iteration suffix
+		podName = sandboxKey(name)
+		c.sandboxMu.Lock()
+		podID, ok := c.sandboxes[podName]
+		c.sandboxMu.Unlock()
+		if ok {
+			return &CRIContainer{
+				name:        name,
+				imageName:   image,
+				cmdOverride: cmdOverride,
+				trace:       trace,
+				podID:       podID,
+			}, nil
+		}
+	}
+
 	var pconfig pb.PodSandboxConfig
-	err := deepCopy(&pconfig, pconfigGlobal)
+	err = deepCopy(&pconfig, pconfigGlobal)
 	if err != nil {
 		return nil, err
 	}
-	pconfig.Metadata.Name = defaultPodNamePrefix + name
+	pconfig.Metadata.Name = defaultPodNamePrefix + podName
 
+	sandboxStart := time.Now()
 	podInfo, err := (*c.runtimeClient).RunPodSandbox(ctx, &pb.RunPodSandboxRequest{Config: &pconfig})
 	if err != nil {
 		return nil, err
 	}
 
+	if c.sandboxReuse {
+		c.sandboxMu.Lock()
+		c.sandboxes[podName] = podInfo.GetPodSandboxId()
+		c.sandboxMu.Unlock()
+	}
+
 	containerObj := &CRIContainer{
 		name:        name,
 		imageName:   image,
@@ -189,18 +293,42 @@ func (c *CRIDriver) Create(ctx context.Context, name, image, cmdOverride string,
 		podID:       podInfo.GetPodSandboxId(),
 	}
 
+	// record sandbox startup as its own stat key so pod-infrastructure
+	// overhead can be separated from container create/start in reports
+	containerObj.recordTiming("sandbox", time.Since(sandboxStart))
+
+	if c.networkBench {
+		// sandbox startup is dominated by network namespace and CNI setup,
+		// so report its duration as the network setup stat key as well
+		containerObj.recordTiming("networkSetup", time.Since(sandboxStart))
+	}
+
 	return containerObj, nil
 }
 
+// sandboxKey maps a container name ("bb-<thread>-<iteration>") to a
+// per-thread sandbox identity by dropping the iteration suffix; names
+// without one (e.g. the validation container) map to themselves
+func sandboxKey(name string) string {
+	parts := strings.Split(name, "-")
+	if len(parts) < 3 {
+		return name
+	}
+	return strings.Join(parts[:len(parts)-1], "-")
+}
+
 // Clean will clean the operating environment of a specific driver
-func (c CRIDriver) Clean(ctx context.Context) error {
+func (c *CRIDriver) Clean(ctx context.Context) error {
 
 	resp, err := (*c.runtimeClient).ListContainers(ctx, &pb.ListContainersRequest{Filter: &pb.ContainerFilter{}})
 	if err != nil {
 		return err
 	}
 	containers := resp.GetContainers()
-	for _, ctr := range containers {
+	// stop and remove leftover containers and their sandboxes in parallel;
+	// serial teardown after a large run takes many minutes
+	cleanupParallel(len(containers), func(i int) {
+		ctr := containers[i]
 		podID := ctr.GetPodSandboxId()
 		_, err := (*c.runtimeClient).StopContainer(ctx, &pb.StopContainerRequest{ContainerId: ctr.GetId(), Timeout: 0})
 		if err != nil {
@@ -214,11 +342,58 @@ func (c CRIDriver) Clean(ctx context.Context) error {
 		if err != nil {
 			log.Errorf("Error deleting pod %s, %v", podID, err)
 		}
+	})
+
+	// sweep leftover bucketbench pod sandboxes; in sandbox-reuse mode the
+	// per-thread pods outlive their containers and are removed here
+	if pods, err := (*c.runtimeClient).ListPodSandbox(ctx, &pb.ListPodSandboxRequest{}); err == nil {
+		items := pods.GetItems()
+		cleanupParallel(len(items), func(i int) {
+			pod := items[i]
+			if !strings.HasPrefix(pod.GetMetadata().GetName(), defaultPodNamePrefix) {
+				return
+			}
+			if _, err := (*c.runtimeClient).StopPodSandbox(ctx, &pb.StopPodSandboxRequest{PodSandboxId: pod.GetId()}); err != nil {
+				log.Errorf("Error stopping pod %s: %v", pod.GetId(), err)
+			}
+			if _, err := (*c.runtimeClient).RemovePodSandbox(ctx, &pb.RemovePodSandboxRequest{PodSandboxId: pod.GetId()}); err != nil {
+				log.Errorf("Error deleting pod %s: %v", pod.GetId(), err)
+			}
+		})
 	}
+	c.sandboxMu.Lock()
+	c.sandboxes = make(map[string]string)
+	c.sandboxMu.Unlock()
+
 	log.Infof("CRI cleanup complete.")
 	return nil
 }
 
+// AuditLeaks lists bb-labelled containers and pod sandboxes still
+// present, for the post-run leak audit
+func (c *CRIDriver) AuditLeaks(ctx context.Context) ([]string, error) {
+	var leaks []string
+	ctrs, err := (*c.runtimeClient).ListContainers(ctx, &pb.ListContainersRequest{})
+	if err != nil {
+		return nil, err
+	}
+	for _, ctr := range ctrs.Containers {
+		if strings.HasPrefix(ctr.GetMetadata().GetName(), ContainerNamePrefix) {
+			leaks = append(leaks, "container "+ctr.GetMetadata().GetName())
+		}
+	}
+	pods, err := (*c.runtimeClient).ListPodSandbox(ctx, &pb.ListPodSandboxRequest{})
+	if err != nil {
+		return leaks, err
+	}
+	for _, pod := range pods.Items {
+		if strings.HasPrefix(pod.GetMetadata().GetName(), defaultPodNamePrefix+ContainerNamePrefix) {
+			leaks = append(leaks, "pod "+pod.GetMetadata().GetName())
+		}
+	}
+	return leaks, nil
+}
+
 // Run will execute a container using the driver
 func (c *CRIDriver) Run(ctx context.Context, ctr Container) (string, time.Duration, error) {
 	var pconfig pb.PodSandboxConfig
@@ -232,13 +407,86 @@ func (c *CRIDriver) Run(ctx context.Context, ctr Container) (string, time.Durati
 		return "", 0, err
 	}
 	cconfig.Metadata.Name = ctr.Name()
+	if len(ctr.Command()) > 0 {
+		// the command override replaces the image (or template) command
+		cconfig.Command = ctr.Command()
+	}
 	pconfig.Metadata.Name = defaultPodNamePrefix + cconfig.Metadata.Name
+
+	// attach any configured host-path mounts; CRI has no named volume
+	// concept so sources must be host paths
+	for _, volume := range c.volumes {
+		parts := strings.SplitN(volume, ":", 2)
+		if len(parts) != 2 {
+			return "", 0, fmt.Errorf("invalid mount %q; expected 'source:dest'", volume)
+		}
+		cconfig.Mounts = append(cconfig.Mounts, &pb.Mount{
+			HostPath:      parts[0],
+			ContainerPath: parts[1],
+		})
+	}
+	for _, mount := range c.mounts {
+		cconfig.Mounts = append(cconfig.Mounts, &pb.Mount{
+			HostPath:      mount.Source,
+			ContainerPath: mount.Destination,
+			Readonly:      mount.ReadOnly,
+		})
+	}
+
+	if c.resources != nil {
+		if cconfig.Linux == nil {
+			cconfig.Linux = &pb.LinuxContainerConfig{}
+		}
+		resources := &pb.LinuxContainerResources{
+			CpuShares:          c.resources.CPUShares,
+			CpuQuota:           c.resources.CPUQuota,
+			MemoryLimitInBytes: c.resources.MemoryBytes,
+		}
+		if c.resources.CPUQuota > 0 {
+			resources.CpuPeriod = defaultCFSPeriod
+		}
+		cconfig.Linux.Resources = resources
+	}
+
+	if c.privileged || len(c.capAdd) > 0 || len(c.capDrop) > 0 {
+		if cconfig.Linux == nil {
+			cconfig.Linux = &pb.LinuxContainerConfig{}
+		}
+		if cconfig.Linux.SecurityContext == nil {
+			cconfig.Linux.SecurityContext = &pb.LinuxContainerSecurityContext{}
+		}
+		cconfig.Linux.SecurityContext.Privileged = c.privileged
+		if len(c.capAdd) > 0 || len(c.capDrop) > 0 {
+			cconfig.Linux.SecurityContext.Capabilities = &pb.Capability{
+				AddCapabilities:  c.capAdd,
+				DropCapabilities: c.capDrop,
+			}
+		}
+	}
+
 	start := time.Now()
 
-	_, err = (*c.runtimeClient).CreateContainer(ctx, &pb.CreateContainerRequest{PodSandboxId: ctr.GetPodID(), Config: &cconfig, SandboxConfig: &pconfig})
+	resp, err := (*c.runtimeClient).CreateContainer(ctx, &pb.CreateContainerRequest{PodSandboxId: ctr.GetPodID(), Config: &cconfig, SandboxConfig: &pconfig})
 	if err != nil {
 		return "", 0, err
 	}
+
+	if c.podBench {
+		// in pod benchmark mode record the create and start steps as
+		// distinct stat keys and actually start the created container
+		created := time.Now()
+		if criCtr, ok := ctr.(*CRIContainer); ok {
+			criCtr.recordTiming("createContainer", created.Sub(start))
+		}
+		_, err = (*c.runtimeClient).StartContainer(ctx, &pb.StartContainerRequest{ContainerId: resp.GetContainerId()})
+		if err != nil {
+			return "", 0, err
+		}
+		if criCtr, ok := ctr.(*CRIContainer); ok {
+			criCtr.recordTiming("startContainer", time.Since(created))
+		}
+	}
+
 	elapsed := time.Since(start)
 	return "", elapsed, nil
 }
@@ -252,18 +500,30 @@ func (c *CRIDriver) Stop(ctx context.Context, ctr Container) (string, time.Durat
 	}
 
 	containers := resp.GetContainers()
-	for _, ctr := range containers {
-		podID := ctr.GetPodSandboxId()
-		_, err := (*c.runtimeClient).StopContainer(ctx, &pb.StopContainerRequest{ContainerId: ctr.GetId(), Timeout: 0})
+	for _, target := range containers {
+		podID := target.GetPodSandboxId()
+		// CRI always stops with the image/runtime default signal; only the
+		// grace timeout is configurable
+		_, err := (*c.runtimeClient).StopContainer(ctx, &pb.StopContainerRequest{ContainerId: target.GetId(), Timeout: int64(c.stopTimeout / time.Second)})
 		if err != nil {
 			log.Errorf("Error Stoping container %v", err)
 			return "", 0, nil
 		}
+		if c.sandboxReuse {
+			// the pod outlives each container in sandbox-reuse mode
+			continue
+		}
+		stopped := time.Now()
 		_, err = (*c.runtimeClient).StopPodSandbox(ctx, &pb.StopPodSandboxRequest{PodSandboxId: podID})
 		if err != nil {
 			log.Errorf("Error Stoping pod %v", err)
 			return "", 0, nil
 		}
+		if c.podBench {
+			if criCtr, ok := ctr.(*CRIContainer); ok {
+				criCtr.recordTiming("stopSandbox", time.Since(stopped))
+			}
+		}
 	}
 	elapsed := time.Since(start)
 	return "", elapsed, nil
@@ -279,23 +539,77 @@ func (c *CRIDriver) Remove(ctx context.Context, ctr Container) (string, time.Dur
 	}
 
 	containers := resp.GetContainers()
-	for _, ctr := range containers {
-		podID := ctr.GetPodSandboxId()
-		_, err = (*c.runtimeClient).RemoveContainer(ctx, &pb.RemoveContainerRequest{ContainerId: ctr.GetId()})
+	for _, target := range containers {
+		podID := target.GetPodSandboxId()
+		_, err = (*c.runtimeClient).RemoveContainer(ctx, &pb.RemoveContainerRequest{ContainerId: target.GetId()})
 		if err != nil {
 			log.Errorf("Error deleting container %v", err)
 			return "", 0, nil
 		}
+		if c.sandboxReuse {
+			// the pod outlives each container in sandbox-reuse mode; Clean
+			// removes the per-thread sandboxes after the run
+			continue
+		}
+		removed := time.Now()
 		_, err = (*c.runtimeClient).RemovePodSandbox(ctx, &pb.RemovePodSandboxRequest{PodSandboxId: podID})
 		if err != nil {
 			log.Errorf("Error deleting pod %v", err)
 			return "", 0, nil
 		}
+		if c.podBench {
+			if criCtr, ok := ctr.(*CRIContainer); ok {
+				criCtr.recordTiming("removeSandbox", time.Since(removed))
+			}
+		}
 	}
 	elapsed := time.Since(start)
 	return "", elapsed, nil
 }
 
+// RemoveImage removes the named image via the CRI image service
+func (c *CRIDriver) RemoveImage(ctx context.Context, image string) error {
+	_, err := (*c.imageClient).RemoveImage(ctx, &pb.RemoveImageRequest{Image: &pb.ImageSpec{Image: image}})
+	return err
+}
+
+// Exec will execute a command inside the container(s) of the pod via the
+// CRI ExecSync API
+func (c *CRIDriver) Exec(ctx context.Context, ctr Container, cmd string) (string, time.Duration, error) {
+	start := time.Now()
+	resp, err := (*c.runtimeClient).ListContainers(ctx, &pb.ListContainersRequest{Filter: &pb.ContainerFilter{PodSandboxId: ctr.GetPodID()}})
+	if err != nil {
+		return "", 0, err
+	}
+
+	var output []byte
+	for _, target := range resp.GetContainers() {
+		execResp, err := (*c.runtimeClient).ExecSync(ctx, &pb.ExecSyncRequest{
+			ContainerId: target.GetId(),
+			Cmd:         strings.Fields(cmd),
+		})
+		if err != nil {
+			return "", 0, err
+		}
+		output = append(output, execResp.GetStdout()...)
+		output = append(output, execResp.GetStderr()...)
+	}
+	elapsed := time.Since(start)
+	return string(output), elapsed, nil
+}
+
+// Checkpoint will checkpoint a running container's state via CRIU
+// not supported in CRI API
+func (c *CRIDriver) Checkpoint(_ context.Context, _ Container) (string, time.Duration, error) {
+	return "", 0, errors.New("not implemented")
+}
+
+// Restore will restore a previously checkpointed container
+// not supported in CRI API
+func (c *CRIDriver) Restore(_ context.Context, _ Container) (string, time.Duration, error) {
+	return "", 0, errors.New("not implemented")
+}
+
 // Pause will pause a container
 // not supported in CRI API
 func (c *CRIDriver) Pause(_ context.Context, _ Container) (string, time.Duration, error) {
@@ -325,8 +639,96 @@ func (c *CRIDriver) Wait(_ context.Context, _ Container) (string, time.Duration,
 }
 
 // Stats returns stats data from daemon for container
-func (c *CRIDriver) Stats(_ context.Context, _ Container) (io.ReadCloser, error) {
-	return nil, errors.New("not implemented")
+func (c *CRIDriver) Stats(ctx context.Context, ctr Container) (io.ReadCloser, error) {
+	resp, err := (*c.runtimeClient).ListContainers(ctx, &pb.ListContainersRequest{Filter: &pb.ContainerFilter{PodSandboxId: ctr.GetPodID()}})
+	if err != nil {
+		return nil, err
+	}
+
+	containers := resp.GetContainers()
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("no containers found in pod %q", ctr.GetPodID())
+	}
+	containerID := containers[0].GetId()
+
+	reader, writer := io.Pipe()
+
+	go func() {
+		ticker := time.NewTicker(c.statsInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				writer.CloseWithError(ctx.Err())
+				return
+			case <-ticker.C:
+				// in pod benchmark mode sample the whole sandbox (which
+				// includes its containers); otherwise just the container
+				var sample interface{}
+				if c.podBench {
+					stats, err := (*c.runtimeClient).PodSandboxStats(ctx, &pb.PodSandboxStatsRequest{PodSandboxId: ctr.GetPodID()})
+					if err != nil {
+						writer.CloseWithError(err)
+						return
+					}
+					sample = stats.GetStats()
+				} else {
+					stats, err := (*c.runtimeClient).ContainerStats(ctx, &pb.ContainerStatsRequest{ContainerId: containerID})
+					if err != nil {
+						writer.CloseWithError(err)
+						return
+					}
+					sample = stats.GetStats()
+				}
+
+				data, err := json.Marshal(sample)
+				if err != nil {
+					writer.CloseWithError(err)
+					return
+				}
+
+				if _, err := writer.Write(data); err != nil {
+					writer.CloseWithError(err)
+					return
+				}
+
+				// If no streaming, run just 1 iteration
+				if !c.streamStats {
+					writer.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	return reader, nil
+}
+
+// Logs returns a reader with the container's log output, read from the
+// log path the runtime recorded in the container status
+func (c *CRIDriver) Logs(ctx context.Context, ctr Container) (io.ReadCloser, error) {
+	resp, err := (*c.runtimeClient).ListContainers(ctx, &pb.ListContainersRequest{Filter: &pb.ContainerFilter{PodSandboxId: ctr.GetPodID()}})
+	if err != nil {
+		return nil, err
+	}
+
+	containers := resp.GetContainers()
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("no containers found in pod %q", ctr.GetPodID())
+	}
+
+	status, err := (*c.runtimeClient).ContainerStatus(ctx, &pb.ContainerStatusRequest{ContainerId: containers[0].GetId()})
+	if err != nil {
+		return nil, err
+	}
+
+	logPath := status.GetStatus().GetLogPath()
+	if logPath == "" {
+		return nil, fmt.Errorf("no log path recorded for container %q", ctr.Name())
+	}
+
+	return os.Open(logPath)
 }
 
 // ProcNames returns the list of process names contributing to mem/cpu usage during overhead benchmark
@@ -334,6 +736,16 @@ func (c *CRIDriver) ProcNames() []string {
 	return []string{}
 }
 
+// DaemonCGroupPath returns the systemd service cgroup of the runtime behind
+// the configured CRI socket, guessed from the socket path (CRI-O vs the
+// containerd CRI plugin), for cgroup-based overhead sampling
+func (c *CRIDriver) DaemonCGroupPath() string {
+	if strings.Contains(c.criSocketAddress, "crio") {
+		return "/system.slice/crio.service"
+	}
+	return "/system.slice/containerd.service"
+}
+
 func openFile(path string) (*os.File, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -345,6 +757,64 @@ func openFile(path string) (*os.File, error) {
 	return f, nil
 }
 
+// resolvePodSandboxConfig loads the pod sandbox template from the configured
+// path when one is set; otherwise it tries the legacy contrib/ location and
+// falls back to a built-in default, so CRI benchmarking works from any
+// working directory
+func resolvePodSandboxConfig(path string) (pb.PodSandboxConfig, error) {
+	if path != "" {
+		return loadPodSandboxConfig(path)
+	}
+	if _, err := os.Stat(defaultSandboxConfig); err == nil {
+		return loadPodSandboxConfig(defaultSandboxConfig)
+	}
+	pconfigGlobal = defaultPodSandboxConfig()
+	return pconfigGlobal, nil
+}
+
+// resolveContainerConfig is the container-config counterpart of
+// resolvePodSandboxConfig
+func resolveContainerConfig(path string) (pb.ContainerConfig, error) {
+	if path != "" {
+		return loadContainerConfig(path)
+	}
+	if _, err := os.Stat(defaultContainerConfig); err == nil {
+		return loadContainerConfig(defaultContainerConfig)
+	}
+	cconfigGlobal = defaultCRIContainerConfig()
+	return cconfigGlobal, nil
+}
+
+// defaultPodSandboxConfig is the built-in pod sandbox template used when no
+// template file is configured or present
+func defaultPodSandboxConfig() pb.PodSandboxConfig {
+	return pb.PodSandboxConfig{
+		Metadata: &pb.PodSandboxMetadata{
+			Name:      "bucketbench",
+			Uid:       "bucketbench-test-cri",
+			Namespace: "bucketbench.test.cri",
+			Attempt:   1,
+		},
+		Linux: &pb.LinuxPodSandboxConfig{},
+	}
+}
+
+// defaultCRIContainerConfig is the built-in container template used when no
+// template file is configured or present
+func defaultCRIContainerConfig() pb.ContainerConfig {
+	return pb.ContainerConfig{
+		Metadata: &pb.ContainerMetadata{
+			Name:    "bucketbench.cc",
+			Attempt: 1,
+		},
+		WorkingDir: "/",
+		Envs: []*pb.KeyValue{
+			{Key: "PATH", Value: "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"},
+		},
+		Linux: &pb.LinuxContainerConfig{},
+	}
+}
+
 func loadPodSandboxConfig(path string) (pb.PodSandboxConfig, error) {
 	f, err := openFile(path)
 	if err != nil {
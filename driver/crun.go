@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/estesp/bucketbench/utils"
@@ -20,6 +22,12 @@ const defaultCRunBinary = "crun"
 // At this time there is no understood use case for multi-threaded use of this implementation.
 type CRunDriver struct {
 	crunBinary string
+	stopSignal string
+	globalOpts string
+
+	bundleIsolation bool
+	bundleMu        sync.Mutex
+	bundles         map[string]string
 }
 
 // CRunContainer is an implementation of the container metadata needed for crun
@@ -33,7 +41,8 @@ type CRunContainer struct {
 }
 
 // NewCRunDriver creates an instance of the crun driver, providing a path to crun
-func NewCRunDriver(binaryPath string) (Driver, error) {
+func NewCRunDriver(config *Config) (Driver, error) {
+	binaryPath := config.Path
 	if binaryPath == "" {
 		binaryPath = defaultCRunBinary
 	}
@@ -42,7 +51,11 @@ func NewCRunDriver(binaryPath string) (Driver, error) {
 		return &CRunDriver{}, err
 	}
 	driver := &CRunDriver{
-		crunBinary: resolvedBinPath,
+		crunBinary:      resolvedBinPath,
+		stopSignal:      stopSignalName(config.StopSignal, "KILL"),
+		globalOpts:      runtimeGlobalOpts(config),
+		bundleIsolation: config.BundleIsolation,
+		bundles:         map[string]string{},
 	}
 	return driver, nil
 }
@@ -80,8 +93,8 @@ func (c *CRunContainer) Image() string {
 
 // Command is not implemented for the crun driver type
 // as the command is embedded in the config.json of the rootfs
-func (c *CRunContainer) Command() string {
-	return ""
+func (c *CRunContainer) Command() []string {
+	return nil
 }
 
 // Pid returns the process ID in cases where this container instance is
@@ -101,6 +114,13 @@ func (c *CRunContainer) GetPodID() string {
 	return ""
 }
 
+// Capabilities reports the optional operations this driver
+// supports: crun retains no log output and has no stats stream, but
+// supports CRIU checkpoint/restore
+func (r *CRunDriver) Capabilities() Capabilities {
+	return Capabilities{Pause: true, Exec: true, Wait: true, Logs: false, Metrics: false, Checkpoint: true}
+}
+
 // Type returns a driver.Type to indentify the driver implementation
 func (r *CRunDriver) Type() Type {
 	return CRun
@@ -122,9 +142,21 @@ func (r *CRunDriver) PID() (int, error) {
 	return 0, errors.New("not implemented")
 }
 
-// Wait will block until container stop
-func (r *CRunDriver) Wait(_ context.Context, _ Container) (string, time.Duration, error) {
-	return "", 0, errors.New("not implemented")
+// Wait blocks until the container reports a stopped state, polling the
+// runtime's state query; a state error after the container exits means it
+// was already deleted, which also ends the wait
+func (r *CRunDriver) Wait(ctx context.Context, ctr Container) (string, time.Duration, error) {
+	start := time.Now()
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", 0, err
+		}
+		out, err := utils.ExecCmd(ctx, r.crunBinary, r.globalOpts+"state "+ctr.Name())
+		if err != nil || strings.Contains(out, "stopped") {
+			return out, time.Since(start), nil
+		}
+		time.Sleep(waitPollInterval)
+	}
 }
 
 // Stats returns stats data from daemon for container
@@ -132,6 +164,27 @@ func (r *CRunDriver) Stats(_ context.Context, _ Container) (io.ReadCloser, error
 	return nil, errors.New("not implemented")
 }
 
+// ContainerMetrics takes a one-shot measurement of the container's
+// memory and CPU usage by reading its cgroup directly, since crun
+// has no daemon to query for stats
+func (r *CRunDriver) ContainerMetrics(ctx context.Context, ctr Container) (uint64, uint64, error) {
+	out, err := utils.ExecCmd(ctx, r.crunBinary, r.globalOpts+"state "+ctr.Name())
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get crun state for %q: %v", ctr.Name(), err)
+	}
+	pid, err := statePid(out)
+	if err != nil {
+		return 0, 0, err
+	}
+	return cgroupMetricsForPID(pid)
+}
+
+// Logs returns log output for container; crun does not retain container
+// log output so this is unsupported
+func (r *CRunDriver) Logs(_ context.Context, _ Container) (io.ReadCloser, error) {
+	return nil, errors.New("not implemented")
+}
+
 // ProcNames returns the list of process names contributing to mem/cpu usage during overhead benchmark
 func (r *CRunDriver) ProcNames() []string {
 	return []string{}
@@ -149,14 +202,39 @@ func (r *CRunDriver) Info(ctx context.Context) (string, error) {
 
 // Create will create a container instance matching the specific needs
 // of a driver
-func (r *CRunDriver) Create(_ context.Context, name, image, _ string, detached bool, _ bool) (Container, error) {
-	return newCRunContainer(name, image, detached, false), nil
+func (r *CRunDriver) Create(ctx context.Context, name, image string, _ []string, detached bool, _ bool) (Container, error) {
+	bundle := image
+	if r.bundleIsolation {
+		var err error
+		if bundle, err = r.isolatedBundle(ctx, name, image); err != nil {
+			return nil, err
+		}
+	}
+	return newCRunContainer(name, bundle, detached, false), nil
+}
+
+// isolatedBundle returns the per-thread copy of the source bundle for
+// the named container, cloning it on first use so concurrent containers
+// don't share one mutable rootfs
+func (r *CRunDriver) isolatedBundle(ctx context.Context, name, src string) (string, error) {
+	key := bundleKey(name)
+	r.bundleMu.Lock()
+	defer r.bundleMu.Unlock()
+	if bundle, ok := r.bundles[key]; ok {
+		return bundle, nil
+	}
+	dst := "/tmp/bb-bundle-crun-" + key
+	if err := cloneBundle(ctx, src, dst); err != nil {
+		return "", err
+	}
+	r.bundles[key] = dst
+	return dst, nil
 }
 
 // Clean will clean the environment; removing any remaining containers in the crun metadata
 func (r *CRunDriver) Clean(ctx context.Context) error {
 	var tries int
-	out, err := utils.ExecCmd(ctx, r.crunBinary, "list")
+	out, err := utils.ExecCmd(ctx, r.crunBinary, r.globalOpts+"list")
 	if err != nil {
 		return fmt.Errorf("Error getting crun list output: (err: %v) output: %s", err, out)
 	}
@@ -183,16 +261,38 @@ func (r *CRunDriver) Clean(ctx context.Context) error {
 			}
 		}
 		tries++
-		out, err := utils.ExecCmd(ctx, r.crunBinary, "list")
+		out, err := utils.ExecCmd(ctx, r.crunBinary, r.globalOpts+"list")
 		if err != nil {
 			return fmt.Errorf("Error getting crun list output: %v", err)
 		}
 		containers = parseCRunList(out)
 	}
+	r.bundleMu.Lock()
+	for _, bundle := range r.bundles {
+		if err := os.RemoveAll(bundle); err != nil {
+			log.Warnf("Could not remove bundle copy %q: %v", bundle, err)
+		}
+	}
+	r.bundles = map[string]string{}
+	r.bundleMu.Unlock()
 	log.Infof("crun cleanup complete.")
 	return nil
 }
 
+// AuditLeaks lists containers still present in the crun state
+// directory, for the post-run leak audit
+func (r *CRunDriver) AuditLeaks(ctx context.Context) ([]string, error) {
+	out, err := utils.ExecCmd(ctx, r.crunBinary, r.globalOpts+"list")
+	if err != nil {
+		return nil, err
+	}
+	var leaks []string
+	for _, ctr := range parseCRunList(out) {
+		leaks = append(leaks, fmt.Sprintf("container %s (%s)", ctr.Name(), ctr.State()))
+	}
+	return leaks, nil
+}
+
 // Run will execute a container using the driver. Note that if the container is specified to
 // run detached, but the config.json for the bundle specifies a "tty" allocation, this
 // crun invocation will fail due to the fact we cannot detach without providing a "--console"
@@ -205,29 +305,45 @@ func (r *CRunDriver) Run(ctx context.Context, ctr Container) (string, time.Durat
 		detached = "--detach"
 	}
 
-	args := fmt.Sprintf("run %s --bundle %s %s", detached, ctr.Image(), ctr.Name())
+	args := fmt.Sprintf("%srun %s --bundle %s %s", r.globalOpts, detached, ctr.Image(), ctr.Name())
 	// the "NoOut" variant of ExecTimedCmd ignores stdin/out/err (sets them to /dev/null)
 	return utils.ExecTimedCmdNoOut(ctx, r.crunBinary, args)
 }
 
 // Stop will stop/kill a container
 func (r *CRunDriver) Stop(ctx context.Context, ctr Container) (string, time.Duration, error) {
-	return utils.ExecTimedCmd(ctx, r.crunBinary, "kill "+ctr.Name()+" KILL")
+	return utils.ExecTimedCmd(ctx, r.crunBinary, r.globalOpts+"kill "+ctr.Name()+" "+r.stopSignal)
 }
 
 // Remove will remove a container
 func (r *CRunDriver) Remove(ctx context.Context, ctr Container) (string, time.Duration, error) {
-	return utils.ExecTimedCmd(ctx, r.crunBinary, "delete "+ctr.Name())
+	return utils.ExecTimedCmd(ctx, r.crunBinary, r.globalOpts+"delete "+ctr.Name())
+}
+
+// Exec will execute a command inside a running container
+func (r *CRunDriver) Exec(ctx context.Context, ctr Container, cmd string) (string, time.Duration, error) {
+	return utils.ExecTimedCmd(ctx, r.crunBinary, r.globalOpts+"exec "+ctr.Name()+" "+cmd)
+}
+
+// Checkpoint will checkpoint a running container's state via CRIU
+func (r *CRunDriver) Checkpoint(ctx context.Context, ctr Container) (string, time.Duration, error) {
+	return utils.ExecTimedCmd(ctx, r.crunBinary, r.globalOpts+"checkpoint --image-path "+criuImagePath(ctr.Name())+" "+ctr.Name())
+}
+
+// Restore will restore a previously checkpointed container
+func (r *CRunDriver) Restore(ctx context.Context, ctr Container) (string, time.Duration, error) {
+	args := fmt.Sprintf("%srestore --detach --image-path %s --bundle %s %s", r.globalOpts, criuImagePath(ctr.Name()), ctr.Image(), ctr.Name())
+	return utils.ExecTimedCmdNoOut(ctx, r.crunBinary, args)
 }
 
 // Pause will pause a container
 func (r *CRunDriver) Pause(ctx context.Context, ctr Container) (string, time.Duration, error) {
-	return utils.ExecTimedCmd(ctx, r.crunBinary, "pause "+ctr.Name())
+	return utils.ExecTimedCmd(ctx, r.crunBinary, r.globalOpts+"pause "+ctr.Name())
 }
 
 // Unpause will unpause/resume a container
 func (r *CRunDriver) Unpause(ctx context.Context, ctr Container) (string, time.Duration, error) {
-	return utils.ExecTimedCmd(ctx, r.crunBinary, "resume "+ctr.Name())
+	return utils.ExecTimedCmd(ctx, r.crunBinary, r.globalOpts+"resume "+ctr.Name())
 }
 
 // take the output of "crun list" and parse into container instances
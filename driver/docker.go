@@ -1,19 +1,27 @@
 package driver
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/strslice"
+	"github.com/docker/docker/api/types/volume"
 	docker "github.com/docker/docker/client"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 const (
@@ -25,14 +33,49 @@ const (
 
 // DockerDriver is an implementation of the driver interface for the Docker engine using API
 type DockerDriver struct {
-	client      *docker.Client
-	logConfig   *container.LogConfig
-	streamStats bool
+	client        *docker.Client
+	logConfig     *container.LogConfig
+	streamStats   bool
+	network       string
+	volumes       []string
+	mounts        []Mount
+	resources     *Resources
+	privileged    bool
+	capAdd        []string
+	capDrop       []string
+	autoRemove    bool
+	restartPolicy string
+	runtime       string
+	waitHealthy   bool
+	registryAuth  *RegistryAuth
+	platform      string
+	pullPolicy    string
+	stopSignal    string
+	stopTimeout   time.Duration
+
+	digestMu    sync.Mutex
+	imageDigest string
+
+	// events correlates API acknowledgements with the engine's matching
+	// create/start/die events when event timings are enabled; nil otherwise
+	events *eventCorrelator
 }
 
 // NewDockerDriver creates an instance of Docker API driver.
 func NewDockerDriver(ctx context.Context, config *Config) (*DockerDriver, error) {
-	client, err := docker.NewClientWithOpts(docker.FromEnv, docker.WithAPIVersionNegotiation())
+	// environment defaults (DOCKER_HOST et al.) apply first so explicit
+	// config can override them for remote daemons
+	opts := []docker.Opt{docker.FromEnv, docker.WithAPIVersionNegotiation()}
+	if config.DockerHost != "" {
+		opts = append(opts, docker.WithHost(config.DockerHost))
+	}
+	if config.DockerTLSCertPath != "" {
+		opts = append(opts, docker.WithTLSClientConfig(
+			filepath.Join(config.DockerTLSCertPath, "ca.pem"),
+			filepath.Join(config.DockerTLSCertPath, "cert.pem"),
+			filepath.Join(config.DockerTLSCertPath, "key.pem")))
+	}
+	client, err := docker.NewClientWithOpts(opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -46,8 +89,24 @@ func NewDockerDriver(ctx context.Context, config *Config) (*DockerDriver, error)
 	client.NegotiateAPIVersionPing(ping)
 
 	driver := &DockerDriver{
-		client:      client,
-		streamStats: config.StreamStats,
+		client:        client,
+		streamStats:   config.StreamStats,
+		network:       config.Network,
+		volumes:       config.Volumes,
+		mounts:        config.Mounts,
+		resources:     config.Resources,
+		privileged:    config.Privileged,
+		capAdd:        config.CapAdd,
+		capDrop:       config.CapDrop,
+		autoRemove:    config.AutoRemove,
+		restartPolicy: config.RestartPolicy,
+		runtime:       config.Runtime,
+		waitHealthy:   config.WaitHealthy,
+		registryAuth:  config.RegistryAuth,
+		platform:      config.Platform,
+		pullPolicy:    config.PullPolicy,
+		stopSignal:    config.StopSignal,
+		stopTimeout:   config.StopTimeout,
 	}
 
 	if config.LogDriver != "" {
@@ -57,9 +116,55 @@ func NewDockerDriver(ctx context.Context, config *Config) (*DockerDriver, error)
 		}
 	}
 
+	if config.EventTimings {
+		driver.events = newEventCorrelator()
+		go driver.monitorEvents(context.Background())
+	}
+
 	return driver, nil
 }
 
+// monitorEvents consumes the Docker events stream and resolves the gap
+// between each API acknowledgement and the engine's matching container
+// event for benchmark containers
+func (d *DockerDriver) monitorEvents(ctx context.Context) {
+	filter := filters.NewArgs(filters.Arg("type", "container"))
+	ch, errs := d.client.Events(ctx, types.EventsOptions{Filters: filter})
+	for {
+		select {
+		case msg := <-ch:
+			name := msg.Actor.Attributes["name"]
+			if !strings.HasPrefix(name, ContainerNamePrefix) {
+				continue
+			}
+			var key string
+			switch msg.Action {
+			case "create":
+				key = "createEvent"
+			case "start":
+				key = "startEvent"
+			case "die":
+				key = "dieEvent"
+			default:
+				continue
+			}
+			d.events.observe(name, key, time.Unix(0, msg.TimeNano))
+		case err := <-errs:
+			if err != nil && err != io.EOF {
+				log.Debugf("event timings: stream ended: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// Capabilities reports the optional operations this driver
+// supports: Docker supports the full command set, including experimental
+// checkpoint/restore when the daemon has it enabled
+func (d *DockerDriver) Capabilities() Capabilities {
+	return Capabilities{Pause: true, Exec: true, Wait: true, Logs: true, Metrics: true, Checkpoint: true}
+}
+
 // Type returns a driver.Type to indentify the driver implementation
 func (d *DockerDriver) Type() Type {
 	return Docker
@@ -81,7 +186,7 @@ func (d *DockerDriver) Path() string {
 }
 
 // Create will pull and create a container instance matching the specific needs of a driver
-func (d *DockerDriver) Create(ctx context.Context, name, image, cmdOverride string, detached bool, trace bool) (Container, error) {
+func (d *DockerDriver) Create(ctx context.Context, name, image string, cmdOverride []string, detached bool, trace bool) (Container, error) {
 	// Make sure the Docker image is available locally
 	images, err := d.client.ImageList(ctx, types.ImageListOptions{
 		Filters: filters.NewArgs(filters.Arg("reference", image)),
@@ -91,8 +196,31 @@ func (d *DockerDriver) Create(ctx context.Context, name, image, cmdOverride stri
 		return nil, errors.Wrap(err, "failed to query image list")
 	}
 
-	if len(images) == 0 {
-		reader, err := d.client.ImagePull(ctx, image, types.ImagePullOptions{})
+	pull := len(images) == 0
+	switch d.pullPolicy {
+	case PullAlways:
+		pull = true
+	case PullNever:
+		if len(images) == 0 {
+			return nil, fmt.Errorf("image '%s' not present and pull policy is %q", image, PullNever)
+		}
+		pull = false
+	}
+
+	if pull {
+		pullOptions := types.ImagePullOptions{Platform: d.platform}
+		username, password, err := d.registryAuth.Resolve(image)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to resolve registry credentials")
+		}
+		if username != "" {
+			authJSON, err := json.Marshal(types.AuthConfig{Username: username, Password: password})
+			if err != nil {
+				return nil, err
+			}
+			pullOptions.RegistryAuth = base64.URLEncoding.EncodeToString(authJSON)
+		}
+		reader, err := d.client.ImagePull(ctx, image, pullOptions)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to pull image: '%s'", image)
 		}
@@ -103,9 +231,45 @@ func (d *DockerDriver) Create(ctx context.Context, name, image, cmdOverride stri
 		io.Copy(io.Discard, reader)
 	}
 
+	// record the digest the reference resolved to so results capture the
+	// exact image variant measured
+	if inspect, _, err := d.client.ImageInspectWithRaw(ctx, image); err == nil {
+		digest := inspect.ID
+		if len(inspect.RepoDigests) > 0 {
+			digest = inspect.RepoDigests[0]
+		}
+		d.digestMu.Lock()
+		d.imageDigest = digest
+		d.digestMu.Unlock()
+	}
+
 	return newDockerContainer(name, image, cmdOverride, detached, trace), nil
 }
 
+// ImageDigest returns the resolved digest of the benchmark image recorded
+// at create time
+func (d *DockerDriver) ImageDigest() string {
+	d.digestMu.Lock()
+	defer d.digestMu.Unlock()
+	return d.imageDigest
+}
+
+// containerRef returns the identifier used for engine API calls: the
+// engine-assigned ID when one was captured at create time, otherwise the
+// container name
+func containerRef(ctr Container) string {
+	if dctr, ok := ctr.(*DockerContainer); ok && dctr.id != "" {
+		return dctr.id
+	}
+	return ctr.Name()
+}
+
+// RemoveImage removes the named image from the daemon's local store
+func (d *DockerDriver) RemoveImage(ctx context.Context, image string) error {
+	_, err := d.client.ImageRemove(ctx, image, types.ImageRemoveOptions{Force: true, PruneChildren: true})
+	return err
+}
+
 // Clean removes used Docker containers
 func (d *DockerDriver) Clean(ctx context.Context) error {
 	listOpts := types.ContainerListOptions{
@@ -118,17 +282,63 @@ func (d *DockerDriver) Clean(ctx context.Context) error {
 		return err
 	}
 
-	for _, instance := range containers {
+	// remove leftovers in parallel; serial removal makes cleanup after a
+	// large density run take many minutes
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	cleanupParallel(len(containers), func(i int) {
 		rmOpts := types.ContainerRemoveOptions{
 			Force: true,
 		}
 
-		if err := d.client.ContainerRemove(ctx, instance.ID, rmOpts); err != nil {
-			return errors.Wrapf(err, "failed to remove instance with id '%s'", instance.ID)
+		if err := d.client.ContainerRemove(ctx, containers[i].ID, rmOpts); err != nil {
+			mu.Lock()
+			if firstErr == nil {
+				firstErr = errors.Wrapf(err, "failed to remove instance with id '%s'", containers[i].ID)
+			}
+			mu.Unlock()
 		}
+	})
+
+	return firstErr
+}
+
+// AuditLeaks lists bb-labelled containers still present, for the
+// post-run leak audit
+func (d *DockerDriver) AuditLeaks(ctx context.Context) ([]string, error) {
+	listOpts := types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("name", ContainerNamePrefix)),
+	}
+	containers, err := d.client.ContainerList(ctx, listOpts)
+	if err != nil {
+		return nil, err
+	}
+	var leaks []string
+	for _, ctr := range containers {
+		name := ctr.ID[:12]
+		if len(ctr.Names) > 0 {
+			name = strings.TrimPrefix(ctr.Names[0], "/")
+		}
+		leaks = append(leaks, fmt.Sprintf("container %s (%s)", name, ctr.State))
 	}
+	return leaks, nil
+}
 
-	return nil
+// parseRestartPolicy splits a CLI-style restart policy string (e.g.
+// "on-failure:2") into the name and maximum retry count the API expects
+func parseRestartPolicy(policy string) (string, int) {
+	name, countStr, ok := strings.Cut(policy, ":")
+	if !ok {
+		return policy, 0
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil {
+		return policy, 0
+	}
+	return name, count
 }
 
 // Run creates a new Docker container and sends a request to the daemon to start it
@@ -140,24 +350,129 @@ func (d *DockerDriver) Run(ctx context.Context, ctr Container) (string, time.Dur
 
 	config.Image = ctr.Image()
 
-	if ctr.Command() != "" {
-		config.Cmd = strings.Fields(ctr.Command())
+	if len(ctr.Command()) > 0 {
+		config.Cmd = strslice.StrSlice(ctr.Command())
 	}
 
 	if d.logConfig != nil {
 		hostConfig.LogConfig = *d.logConfig
 	}
 
-	if _, err := d.client.ContainerCreate(ctx, &config, &hostConfig, nil, nil, ctr.Name()); err != nil {
+	if len(d.volumes) > 0 {
+		hostConfig.Binds = d.volumes
+	}
+	for _, mount := range d.mounts {
+		bind := fmt.Sprintf("%s:%s", mount.Source, mount.Destination)
+		if mount.ReadOnly {
+			bind += ":ro"
+		}
+		hostConfig.Binds = append(hostConfig.Binds, bind)
+	}
+
+	if d.resources != nil {
+		hostConfig.CPUShares = d.resources.CPUShares
+		hostConfig.CPUQuota = d.resources.CPUQuota
+		hostConfig.Memory = d.resources.MemoryBytes
+	}
+
+	hostConfig.Privileged = d.privileged
+	if len(d.capAdd) > 0 {
+		hostConfig.CapAdd = strslice.StrSlice(d.capAdd)
+	}
+	if len(d.capDrop) > 0 {
+		hostConfig.CapDrop = strslice.StrSlice(d.capDrop)
+	}
+
+	hostConfig.AutoRemove = d.autoRemove
+	if d.restartPolicy != "" {
+		name, retries := parseRestartPolicy(d.restartPolicy)
+		hostConfig.RestartPolicy = container.RestartPolicy{Name: name, MaximumRetryCount: retries}
+	}
+	if d.runtime != "" {
+		hostConfig.Runtime = d.runtime
+	}
+
+	// when benchmarking a real network, start without one so the attach
+	// can be timed as a separate operation below
+	if d.network != "" {
+		hostConfig.NetworkMode = "none"
+	}
+
+	resp, err := d.client.ContainerCreate(ctx, &config, &hostConfig, nil, nil, ctr.Name())
+	if err != nil {
 		return "", 0, errors.Wrapf(err, "couldn't create container '%s'", ctr.Name())
 	}
+	// track the engine-assigned ID so subsequent operations address the
+	// exact instance rather than doing a name lookup
+	if dctr, ok := ctr.(*DockerContainer); ok {
+		dctr.id = resp.ID
+	}
+	created := time.Now()
 
 	opts := types.ContainerStartOptions{}
-	if err := d.client.ContainerStart(ctx, ctr.Name(), opts); err != nil {
+	if err := d.client.ContainerStart(ctx, containerRef(ctr), opts); err != nil {
 		return "", 0, errors.Wrapf(err, "failed to start container '%s'", ctr.Name())
 	}
+	elapsed := time.Since(start)
+
+	// record the create and start steps as distinct stat keys
+	if dockerCtr, ok := ctr.(*DockerContainer); ok {
+		dockerCtr.recordTiming("createContainer", created.Sub(start))
+		dockerCtr.recordTiming("startContainer", time.Since(created))
+		d.events.expect(dockerCtr, ctr.Name(), "createEvent", created)
+		d.events.expect(dockerCtr, ctr.Name(), "startEvent", time.Now())
+	}
 
-	return "", time.Since(start), nil
+	if d.network != "" {
+		netStart := time.Now()
+		if err := d.client.NetworkConnect(ctx, d.network, containerRef(ctr), nil); err != nil {
+			return "", 0, errors.Wrapf(err, "failed to connect container '%s' to network '%s'", ctr.Name(), d.network)
+		}
+		if dockerCtr, ok := ctr.(*DockerContainer); ok {
+			dockerCtr.recordTiming("networkSetup", time.Since(netStart))
+		}
+	}
+
+	if d.waitHealthy {
+		healthStart := time.Now()
+		if err := d.waitForHealthy(ctx, ctr); err != nil {
+			return "", 0, err
+		}
+		if dockerCtr, ok := ctr.(*DockerContainer); ok {
+			dockerCtr.recordTiming("healthy", time.Since(healthStart))
+		}
+		// "ready" for a healthchecked workload means healthy, not started
+		elapsed = time.Since(start)
+	}
+
+	return "", elapsed, nil
+}
+
+// waitForHealthy polls the container state until its HEALTHCHECK reports
+// healthy; containers without a configured healthcheck return as soon as
+// they are running so mixed image sets don't stall the benchmark
+func (d *DockerDriver) waitForHealthy(ctx context.Context, ctr Container) error {
+	for {
+		info, err := d.client.ContainerInspect(ctx, containerRef(ctr))
+		if err != nil {
+			return errors.Wrapf(err, "failed inspecting container '%s' for health", ctr.Name())
+		}
+		if info.State == nil || info.State.Health == nil {
+			// no HEALTHCHECK configured; running is as ready as we can know
+			return nil
+		}
+		switch info.State.Health.Status {
+		case types.Healthy:
+			return nil
+		case types.Unhealthy:
+			return errors.Errorf("container '%s' reported unhealthy", ctr.Name())
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(waitPollInterval):
+		}
+	}
 }
 
 // Stop stops a container
@@ -165,12 +480,19 @@ func (d *DockerDriver) Stop(ctx context.Context, ctr Container) (string, time.Du
 	start := time.Now()
 
 	timeout := dockerContainerStopTimeout
+	if d.stopTimeout > 0 {
+		timeout = int(d.stopTimeout / time.Second)
+	}
 	stop := container.StopOptions{
 		Timeout: &timeout,
+		Signal:  d.stopSignal,
 	}
-	if err := d.client.ContainerStop(ctx, ctr.Name(), stop); err != nil {
+	if err := d.client.ContainerStop(ctx, containerRef(ctr), stop); err != nil {
 		return "", 0, errors.Wrapf(err, "failed to stop container '%s'", ctr.Name())
 	}
+	if dockerCtr, ok := ctr.(*DockerContainer); ok {
+		d.events.expect(dockerCtr, ctr.Name(), "dieEvent", time.Now())
+	}
 
 	return "", time.Since(start), nil
 }
@@ -180,18 +502,96 @@ func (d *DockerDriver) Remove(ctx context.Context, ctr Container) (string, time.
 	start := time.Now()
 
 	opts := types.ContainerRemoveOptions{Force: true}
-	if err := d.client.ContainerRemove(ctx, ctr.Name(), opts); err != nil {
+	if err := d.client.ContainerRemove(ctx, containerRef(ctr), opts); err != nil {
 		return "", 0, errors.Wrapf(err, "failed to remove container: '%s'", ctr.Name())
 	}
 
 	return "", time.Since(start), nil
 }
 
+// CreateVolume creates a named volume in the docker volume store
+func (d *DockerDriver) CreateVolume(ctx context.Context, name string) (string, time.Duration, error) {
+	start := time.Now()
+
+	if _, err := d.client.VolumeCreate(ctx, volume.CreateOptions{Name: name}); err != nil {
+		return "", 0, errors.Wrapf(err, "failed to create volume '%s'", name)
+	}
+
+	return "", time.Since(start), nil
+}
+
+// RemoveVolume removes a named volume from the docker volume store
+func (d *DockerDriver) RemoveVolume(ctx context.Context, name string) (string, time.Duration, error) {
+	start := time.Now()
+
+	if err := d.client.VolumeRemove(ctx, name, true); err != nil {
+		return "", 0, errors.Wrapf(err, "failed to remove volume '%s'", name)
+	}
+
+	return "", time.Since(start), nil
+}
+
+// Exec executes a command inside a running container and waits for its output
+func (d *DockerDriver) Exec(ctx context.Context, ctr Container, cmd string) (string, time.Duration, error) {
+	start := time.Now()
+
+	execConfig := types.ExecConfig{
+		Cmd:          strings.Fields(cmd),
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+	resp, err := d.client.ContainerExecCreate(ctx, containerRef(ctr), execConfig)
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "failed to create exec in container '%s'", ctr.Name())
+	}
+
+	attach, err := d.client.ContainerExecAttach(ctx, resp.ID, types.ExecStartCheck{})
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "failed to start exec in container '%s'", ctr.Name())
+	}
+	defer attach.Close()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, attach.Reader); err != nil {
+		return buf.String(), 0, errors.Wrapf(err, "failed reading exec output from container '%s'", ctr.Name())
+	}
+
+	return buf.String(), time.Since(start), nil
+}
+
+// Checkpoint will checkpoint a running container's state via CRIU; this
+// requires dockerd running with experimental features enabled
+func (d *DockerDriver) Checkpoint(ctx context.Context, ctr Container) (string, time.Duration, error) {
+	start := time.Now()
+
+	opts := types.CheckpointCreateOptions{
+		CheckpointID: dockerCheckpointName,
+		Exit:         true,
+	}
+	if err := d.client.CheckpointCreate(ctx, containerRef(ctr), opts); err != nil {
+		return "", 0, errors.Wrapf(err, "failed to checkpoint container '%s'", ctr.Name())
+	}
+
+	return "", time.Since(start), nil
+}
+
+// Restore will restore a previously checkpointed container
+func (d *DockerDriver) Restore(ctx context.Context, ctr Container) (string, time.Duration, error) {
+	start := time.Now()
+
+	opts := types.ContainerStartOptions{CheckpointID: dockerCheckpointName}
+	if err := d.client.ContainerStart(ctx, containerRef(ctr), opts); err != nil {
+		return "", 0, errors.Wrapf(err, "failed to restore container '%s'", ctr.Name())
+	}
+
+	return "", time.Since(start), nil
+}
+
 // Pause pauses a container
 func (d *DockerDriver) Pause(ctx context.Context, ctr Container) (string, time.Duration, error) {
 	start := time.Now()
 
-	if err := d.client.ContainerPause(ctx, ctr.Name()); err != nil {
+	if err := d.client.ContainerPause(ctx, containerRef(ctr)); err != nil {
 		return "", 0, nil
 	}
 
@@ -202,7 +602,7 @@ func (d *DockerDriver) Pause(ctx context.Context, ctr Container) (string, time.D
 func (d *DockerDriver) Unpause(ctx context.Context, ctr Container) (string, time.Duration, error) {
 	start := time.Now()
 
-	if err := d.client.ContainerUnpause(ctx, ctr.Name()); err != nil {
+	if err := d.client.ContainerUnpause(ctx, containerRef(ctr)); err != nil {
 		return "", 0, errors.Wrapf(err, "failed to unpause container: '%s'", ctr.Name())
 	}
 
@@ -213,14 +613,31 @@ func (d *DockerDriver) Unpause(ctx context.Context, ctr Container) (string, time
 func (d *DockerDriver) Wait(ctx context.Context, ctr Container) (string, time.Duration, error) {
 	start := time.Now()
 
-	waitC, errC := d.client.ContainerWait(ctx, ctr.Name(), container.WaitConditionNotRunning)
+	waitC, errC := d.client.ContainerWait(ctx, containerRef(ctr), container.WaitConditionNotRunning)
 
 	select {
 	case err := <-errC:
 		return "", 0, errors.Wrapf(err, "failed to wait container: '%s'", ctr.Name())
-	case <-waitC:
-		return "", time.Since(start), nil
+	case resp := <-waitC:
+		// report the exit code as output so expectations can assert on it
+		return strconv.FormatInt(resp.StatusCode, 10), time.Since(start), nil
+	}
+}
+
+// ContainerMetrics takes a one-shot stats sample of the container,
+// returning current memory usage and cumulative CPU time
+func (d *DockerDriver) ContainerMetrics(ctx context.Context, ctr Container) (uint64, uint64, error) {
+	resp, err := d.client.ContainerStatsOneShot(ctx, containerRef(ctr))
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "failed to get stats for container: '%s'", ctr.Name())
+	}
+	defer resp.Body.Close()
+
+	var statsJSON types.StatsJSON
+	if err := json.NewDecoder(resp.Body).Decode(&statsJSON); err != nil {
+		return 0, 0, errors.Wrapf(err, "failed decoding stats for container: '%s'", ctr.Name())
 	}
+	return statsJSON.MemoryStats.Usage, statsJSON.CPUStats.CPUUsage.TotalUsage, nil
 }
 
 // Close closes the transport used by Docker client
@@ -238,9 +655,15 @@ func (d *DockerDriver) ProcNames() []string {
 	return dockerProcNames
 }
 
+// DaemonCGroupPath returns the systemd service cgroup dockerd runs under,
+// used for cgroup-based overhead sampling when no explicit path is given
+func (d *DockerDriver) DaemonCGroupPath() string {
+	return "/system.slice/docker.service"
+}
+
 // Stats returns stats data from daemon for container
 func (d *DockerDriver) Stats(ctx context.Context, ctr Container) (io.ReadCloser, error) {
-	stats, err := d.client.ContainerStats(ctx, ctr.Name(), d.streamStats)
+	stats, err := d.client.ContainerStats(ctx, containerRef(ctr), d.streamStats)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to get stats for container: '%s'", ctr.Name())
 	}
@@ -270,6 +693,17 @@ func (d *DockerDriver) Stats(ctx context.Context, ctr Container) (io.ReadCloser,
 	return reader, nil
 }
 
+// Logs returns a reader with the container's log output
+func (d *DockerDriver) Logs(ctx context.Context, ctr Container) (io.ReadCloser, error) {
+	opts := types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true}
+	reader, err := d.client.ContainerLogs(ctx, containerRef(ctr), opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get logs for container: '%s'", ctr.Name())
+	}
+
+	return reader, nil
+}
+
 func getDockerPID(path string) (int, error) {
 	if path == "" {
 		path = dockerDefaultPIDPath
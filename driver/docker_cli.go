@@ -5,7 +5,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/estesp/bucketbench/utils"
@@ -13,7 +15,12 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-const defaultDockerBinary = "docker"
+const (
+	defaultDockerBinary = "docker"
+	// name used for the checkpoint created/restored by the checkpoint and
+	// restore benchmark commands (experimental dockerd feature)
+	dockerCheckpointName = "bb-checkpoint"
+)
 
 var dockerProcNames = []string{
 	"dockerd",
@@ -26,20 +33,61 @@ var dockerProcNames = []string{
 // IMPORTANT: This implementation does not protect instance metadata for thread safely.
 // At this time there is no understood use case for multi-threaded use of this implementation.
 type DockerCLIDriver struct {
-	dockerBinary string
-	dockerInfo   string
-	logDriver    string
-	logOpts      map[string]string
-	streamStats  bool
+	dockerBinary  string
+	dockerInfo    string
+	logDriver     string
+	logOpts       map[string]string
+	streamStats   bool
+	network       string
+	volumes       []string
+	mounts        []Mount
+	resources     *Resources
+	privileged    bool
+	capAdd        []string
+	capDrop       []string
+	autoRemove    bool
+	restartPolicy string
+	runtime       string
+	stopSignal    string
+	stopTimeout   time.Duration
 }
 
 // DockerContainer is an implementation of the container metadata needed for docker
 type DockerContainer struct {
 	name        string
 	imageName   string
-	cmdOverride string
+	cmdOverride []string
 	detached    bool
 	trace       bool
+	// id is the engine-assigned container ID, captured on create by the
+	// API driver so later operations address the exact instance rather
+	// than relying on name lookup
+	id string
+	// timingMu guards timings: the event monitor goroutine records event
+	// latencies concurrently with the benchmark thread's own timings
+	timingMu sync.Mutex
+	timings  map[string]time.Duration
+}
+
+// recordTiming stores a fine-grained operation duration for later retrieval
+// via the OperationTimings interface
+func (c *DockerContainer) recordTiming(name string, elapsed time.Duration) {
+	c.timingMu.Lock()
+	defer c.timingMu.Unlock()
+	if c.timings == nil {
+		c.timings = make(map[string]time.Duration)
+	}
+	c.timings[name] = elapsed
+}
+
+// Timings returns and clears the fine-grained operation durations recorded
+// since the last call
+func (c *DockerContainer) Timings() map[string]time.Duration {
+	c.timingMu.Lock()
+	defer c.timingMu.Unlock()
+	timings := c.timings
+	c.timings = nil
+	return timings
 }
 
 // NewDockerCLIDriver creates an instance of the docker driver, providing a path to the docker client binary
@@ -55,10 +103,22 @@ func NewDockerCLIDriver(ctx context.Context, config *Config) (Driver, error) {
 	}
 
 	driver := &DockerCLIDriver{
-		dockerBinary: resolvedBinPath,
-		logDriver:    config.LogDriver,
-		logOpts:      config.LogOpts,
-		streamStats:  config.StreamStats,
+		dockerBinary:  resolvedBinPath,
+		logDriver:     config.LogDriver,
+		logOpts:       config.LogOpts,
+		streamStats:   config.StreamStats,
+		network:       config.Network,
+		volumes:       config.Volumes,
+		mounts:        config.Mounts,
+		resources:     config.Resources,
+		privileged:    config.Privileged,
+		capAdd:        config.CapAdd,
+		capDrop:       config.CapDrop,
+		autoRemove:    config.AutoRemove,
+		restartPolicy: config.RestartPolicy,
+		runtime:       config.Runtime,
+		stopSignal:    config.StopSignal,
+		stopTimeout:   config.StopTimeout,
 	}
 
 	info, err := driver.Info(ctx)
@@ -72,7 +132,7 @@ func NewDockerCLIDriver(ctx context.Context, config *Config) (Driver, error) {
 
 // newDockerContainer creates the metadata object of a docker-specific container with
 // image name, container runtime name, and any required additional information
-func newDockerContainer(name, image, cmd string, detached bool, trace bool) Container {
+func newDockerContainer(name, image string, cmd []string, detached bool, trace bool) Container {
 	return &DockerContainer{
 		name:        name,
 		imageName:   image,
@@ -104,7 +164,7 @@ func (c *DockerContainer) Image() string {
 
 // Command returns the optional overriding command that Docker will use
 // when executing a container based on this container's image
-func (c *DockerContainer) Command() string {
+func (c *DockerContainer) Command() []string {
 	return c.cmdOverride
 }
 
@@ -114,6 +174,13 @@ func (c *DockerContainer) GetPodID() string {
 	return ""
 }
 
+// Capabilities reports the optional operations this driver
+// supports: the Docker CLI supports the full command set, including
+// experimental checkpoint/restore when the daemon has it enabled
+func (d *DockerCLIDriver) Capabilities() Capabilities {
+	return Capabilities{Pause: true, Exec: true, Wait: true, Logs: true, Metrics: true, Checkpoint: true}
+}
+
 // Type returns a driver.Type to indentify the driver implementation
 func (d *DockerCLIDriver) Type() Type {
 	return DockerCLI
@@ -162,10 +229,19 @@ func (d *DockerCLIDriver) Info(ctx context.Context) (string, error) {
 
 // Create will create a container instance matching the specific needs
 // of a driver
-func (d *DockerCLIDriver) Create(_ context.Context, name, image, cmdOverride string, detached bool, trace bool) (Container, error) {
+func (d *DockerCLIDriver) Create(_ context.Context, name, image string, cmdOverride []string, detached bool, trace bool) (Container, error) {
 	return newDockerContainer(name, image, cmdOverride, detached, trace), nil
 }
 
+// RemoveImage removes the named image from the daemon's local store
+func (d *DockerCLIDriver) RemoveImage(ctx context.Context, image string) error {
+	out, err := utils.ExecCmd(ctx, d.dockerBinary, "rmi -f "+image)
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove image %q: %s", image, out)
+	}
+	return nil
+}
+
 // Clean will clean the environment; removing any exited containers
 func (d *DockerCLIDriver) Clean(ctx context.Context) error {
 	// clean up any containers from a prior run
@@ -192,6 +268,20 @@ func (d *DockerCLIDriver) Clean(ctx context.Context) error {
 	return nil
 }
 
+// AuditLeaks lists bb-labelled containers still present, for the
+// post-run leak audit
+func (d *DockerCLIDriver) AuditLeaks(ctx context.Context) ([]string, error) {
+	out, err := utils.ExecCmd(ctx, d.dockerBinary, "ps -a --format {{.Names}} --filter name="+ContainerNamePrefix)
+	if err != nil {
+		return nil, err
+	}
+	var leaks []string
+	for _, name := range strings.Fields(out) {
+		leaks = append(leaks, "container "+name)
+	}
+	return leaks, nil
+}
+
 // Run will execute a container using the driver
 func (d *DockerCLIDriver) Run(ctx context.Context, ctr Container) (string, time.Duration, error) {
 	args := []string{"run"}
@@ -208,18 +298,88 @@ func (d *DockerCLIDriver) Run(ctx context.Context, ctr Container) (string, time.
 		}
 	}
 
+	// when benchmarking a real network, start without one so the attach
+	// can be timed as a separate operation below
+	if d.network != "" {
+		args = append(args, "--net", "none")
+	}
+
+	for _, volume := range d.volumes {
+		args = append(args, "-v", volume)
+	}
+	for _, mount := range d.mounts {
+		bind := fmt.Sprintf("%s:%s", mount.Source, mount.Destination)
+		if mount.ReadOnly {
+			bind += ":ro"
+		}
+		args = append(args, "-v", bind)
+	}
+
+	if d.resources != nil {
+		if d.resources.CPUShares > 0 {
+			args = append(args, "--cpu-shares", fmt.Sprintf("%d", d.resources.CPUShares))
+		}
+		if d.resources.CPUQuota > 0 {
+			args = append(args, "--cpu-quota", fmt.Sprintf("%d", d.resources.CPUQuota))
+		}
+		if d.resources.MemoryBytes > 0 {
+			args = append(args, "--memory", fmt.Sprintf("%d", d.resources.MemoryBytes))
+		}
+	}
+
+	if d.privileged {
+		args = append(args, "--privileged")
+	}
+	for _, capability := range d.capAdd {
+		args = append(args, "--cap-add", capability)
+	}
+	for _, capability := range d.capDrop {
+		args = append(args, "--cap-drop", capability)
+	}
+
+	if d.autoRemove {
+		args = append(args, "--rm")
+	}
+	if d.restartPolicy != "" {
+		args = append(args, "--restart", d.restartPolicy)
+	}
+	if d.runtime != "" {
+		args = append(args, "--runtime", d.runtime)
+	}
+
 	args = append(args, "--name", ctr.Name(), ctr.Image())
 
-	if ctr.Command() != "" {
-		args = append(args, ctr.Command())
+	if len(ctr.Command()) > 0 {
+		args = append(args, ctr.Command()...)
 	}
 
-	return utils.ExecTimedCmd(ctx, d.dockerBinary, strings.Join(args, " "))
+	out, elapsed, err := utils.ExecTimedCmd(ctx, d.dockerBinary, strings.Join(args, " "))
+	if err != nil || d.network == "" {
+		return out, elapsed, err
+	}
+
+	netOut, netElapsed, err := utils.ExecTimedCmd(ctx, d.dockerBinary, "network connect "+d.network+" "+ctr.Name())
+	if err != nil {
+		return netOut, elapsed, errors.Wrapf(err, "failed to connect container '%s' to network '%s'", ctr.Name(), d.network)
+	}
+	if dockerCtr, ok := ctr.(*DockerContainer); ok {
+		dockerCtr.recordTiming("networkSetup", netElapsed)
+	}
+
+	return out, elapsed, nil
 }
 
 // Stop will stop a container
 func (d *DockerCLIDriver) Stop(ctx context.Context, ctr Container) (string, time.Duration, error) {
-	return utils.ExecTimedCmd(ctx, d.dockerBinary, "stop "+ctr.Name())
+	args := []string{"stop"}
+	if d.stopSignal != "" {
+		args = append(args, "--signal", d.stopSignal)
+	}
+	if d.stopTimeout > 0 {
+		args = append(args, "-t", strconv.Itoa(int(d.stopTimeout/time.Second)))
+	}
+	args = append(args, ctr.Name())
+	return utils.ExecTimedCmd(ctx, d.dockerBinary, strings.Join(args, " "))
 }
 
 // Remove will remove a container
@@ -227,6 +387,32 @@ func (d *DockerCLIDriver) Remove(ctx context.Context, ctr Container) (string, ti
 	return utils.ExecTimedCmd(ctx, d.dockerBinary, "rm "+ctr.Name())
 }
 
+// CreateVolume creates a named volume in the docker volume store
+func (d *DockerCLIDriver) CreateVolume(ctx context.Context, name string) (string, time.Duration, error) {
+	return utils.ExecTimedCmd(ctx, d.dockerBinary, "volume create "+name)
+}
+
+// RemoveVolume removes a named volume from the docker volume store
+func (d *DockerCLIDriver) RemoveVolume(ctx context.Context, name string) (string, time.Duration, error) {
+	return utils.ExecTimedCmd(ctx, d.dockerBinary, "volume rm -f "+name)
+}
+
+// Exec will execute a command inside a running container
+func (d *DockerCLIDriver) Exec(ctx context.Context, ctr Container, cmd string) (string, time.Duration, error) {
+	return utils.ExecTimedCmd(ctx, d.dockerBinary, "exec "+ctr.Name()+" "+cmd)
+}
+
+// Checkpoint will checkpoint a running container's state via CRIU; this
+// requires dockerd running with experimental features enabled
+func (d *DockerCLIDriver) Checkpoint(ctx context.Context, ctr Container) (string, time.Duration, error) {
+	return utils.ExecTimedCmd(ctx, d.dockerBinary, "checkpoint create "+ctr.Name()+" "+dockerCheckpointName)
+}
+
+// Restore will restore a previously checkpointed container
+func (d *DockerCLIDriver) Restore(ctx context.Context, ctr Container) (string, time.Duration, error) {
+	return utils.ExecTimedCmd(ctx, d.dockerBinary, "start --checkpoint "+dockerCheckpointName+" "+ctr.Name())
+}
+
 // Pause will pause a container
 func (d *DockerCLIDriver) Pause(ctx context.Context, ctr Container) (string, time.Duration, error) {
 	return utils.ExecTimedCmd(ctx, d.dockerBinary, "pause "+ctr.Name())
@@ -249,6 +435,11 @@ func (d *DockerCLIDriver) Stats(ctx context.Context, ctr Container) (io.ReadClos
 	return utils.ExecCmdStream(ctx, d.dockerBinary, args)
 }
 
+// Logs returns a reader with the container's log output
+func (d *DockerCLIDriver) Logs(ctx context.Context, ctr Container) (io.ReadCloser, error) {
+	return utils.ExecCmdStream(ctx, d.dockerBinary, "logs "+ctr.Name())
+}
+
 // ProcNames returns the list of process names contributing to mem/cpu usage during overhead benchmark
 func (d *DockerCLIDriver) ProcNames() []string {
 	return dockerProcNames
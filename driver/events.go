@@ -0,0 +1,96 @@
+// Copyright © 2016 Phil Estes <estesp@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driver
+
+import (
+	"sync"
+	"time"
+)
+
+// timingRecorder is implemented by container types which accumulate
+// fine-grained operation timings for the OperationTimings interface
+type timingRecorder interface {
+	recordTiming(name string, elapsed time.Duration)
+}
+
+// eventWait pairs a container awaiting an engine event with the time the
+// triggering API call returned; either side (API return or event arrival)
+// may be recorded first
+type eventWait struct {
+	ctr       timingRecorder
+	apiReturn time.Time
+	eventAt   time.Time
+}
+
+// eventCorrelator matches API-call returns with the engine's matching
+// asynchronous events and records the gap as an operation timing on the
+// container, exposing completion latency that client-side timing of the
+// API call alone cannot see
+type eventCorrelator struct {
+	mu    sync.Mutex
+	waits map[string]*eventWait
+}
+
+func newEventCorrelator() *eventCorrelator {
+	return &eventCorrelator{waits: make(map[string]*eventWait)}
+}
+
+// expect records the time an API call returned for a container, so the
+// matching event (which may already have arrived) yields the completion
+// gap; a nil correlator (event timings disabled) is a no-op
+func (e *eventCorrelator) expect(ctr timingRecorder, id, key string, apiReturn time.Time) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	mapKey := id + "/" + key
+	wait, ok := e.waits[mapKey]
+	if !ok {
+		e.waits[mapKey] = &eventWait{ctr: ctr, apiReturn: apiReturn}
+		return
+	}
+	// the event already arrived; a positive gap only exists when the event
+	// trails the API return
+	gap := wait.eventAt.Sub(apiReturn)
+	if gap < 0 {
+		gap = 0
+	}
+	ctr.recordTiming(key, gap)
+	delete(e.waits, mapKey)
+}
+
+// observe matches an incoming engine event against a pending API return,
+// or records its arrival for an API call still in flight
+func (e *eventCorrelator) observe(id, key string, at time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	mapKey := id + "/" + key
+	wait, ok := e.waits[mapKey]
+	if !ok || wait.ctr == nil {
+		if !ok {
+			wait = &eventWait{}
+			e.waits[mapKey] = wait
+		}
+		wait.eventAt = at
+		return
+	}
+	gap := at.Sub(wait.apiReturn)
+	if gap < 0 {
+		gap = 0
+	}
+	wait.ctr.recordTiming(key, gap)
+	delete(e.waits, mapKey)
+}
@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/estesp/bucketbench/utils"
@@ -20,6 +22,12 @@ const defaultRuncBinary = "runc"
 // At this time there is no understood use case for multi-threaded use of this implementation.
 type RuncDriver struct {
 	runcBinary string
+	stopSignal string
+	globalOpts string
+
+	bundleIsolation bool
+	bundleMu        sync.Mutex
+	bundles         map[string]string
 }
 
 // RuncContainer is an implementation of the container metadata needed for runc
@@ -33,7 +41,8 @@ type RuncContainer struct {
 }
 
 // NewRuncDriver creates an instance of the runc driver, providing a path to runc
-func NewRuncDriver(binaryPath string) (Driver, error) {
+func NewRuncDriver(config *Config) (Driver, error) {
+	binaryPath := config.Path
 	if binaryPath == "" {
 		binaryPath = defaultRuncBinary
 	}
@@ -42,7 +51,11 @@ func NewRuncDriver(binaryPath string) (Driver, error) {
 		return &RuncDriver{}, err
 	}
 	driver := &RuncDriver{
-		runcBinary: resolvedBinPath,
+		runcBinary:      resolvedBinPath,
+		stopSignal:      stopSignalName(config.StopSignal, "KILL"),
+		globalOpts:      runtimeGlobalOpts(config),
+		bundleIsolation: config.BundleIsolation,
+		bundles:         map[string]string{},
 	}
 	return driver, nil
 }
@@ -80,8 +93,8 @@ func (c *RuncContainer) Image() string {
 
 // Command is not implemented for the runc driver type
 // as the command is embedded in the config.json of the rootfs
-func (c *RuncContainer) Command() string {
-	return ""
+func (c *RuncContainer) Command() []string {
+	return nil
 }
 
 // Pid returns the process ID in cases where this container instance is
@@ -101,6 +114,13 @@ func (c *RuncContainer) GetPodID() string {
 	return ""
 }
 
+// Capabilities reports the optional operations this driver
+// supports: runc retains no log output and has no stats stream, but
+// supports CRIU checkpoint/restore
+func (r *RuncDriver) Capabilities() Capabilities {
+	return Capabilities{Pause: true, Exec: true, Wait: true, Logs: false, Metrics: false, Checkpoint: true}
+}
+
 // Type returns a driver.Type to indentify the driver implementation
 func (r *RuncDriver) Type() Type {
 	return Runc
@@ -122,9 +142,21 @@ func (r *RuncDriver) PID() (int, error) {
 	return 0, errors.New("not implemented")
 }
 
-// Wait will block until container stop
-func (r *RuncDriver) Wait(_ context.Context, _ Container) (string, time.Duration, error) {
-	return "", 0, errors.New("not implemented")
+// Wait blocks until the container reports a stopped state, polling the
+// runtime's state query; a state error after the container exits means it
+// was already deleted, which also ends the wait
+func (r *RuncDriver) Wait(ctx context.Context, ctr Container) (string, time.Duration, error) {
+	start := time.Now()
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", 0, err
+		}
+		out, err := utils.ExecCmd(ctx, r.runcBinary, r.globalOpts+"state "+ctr.Name())
+		if err != nil || strings.Contains(out, "stopped") {
+			return out, time.Since(start), nil
+		}
+		time.Sleep(waitPollInterval)
+	}
 }
 
 // Stats returns stats data from daemon for container
@@ -132,6 +164,27 @@ func (r *RuncDriver) Stats(_ context.Context, _ Container) (io.ReadCloser, error
 	return nil, errors.New("not implemented")
 }
 
+// ContainerMetrics takes a one-shot measurement of the container's
+// memory and CPU usage by reading its cgroup directly, since runc
+// has no daemon to query for stats
+func (r *RuncDriver) ContainerMetrics(ctx context.Context, ctr Container) (uint64, uint64, error) {
+	out, err := utils.ExecCmd(ctx, r.runcBinary, r.globalOpts+"state "+ctr.Name())
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get runc state for %q: %v", ctr.Name(), err)
+	}
+	pid, err := statePid(out)
+	if err != nil {
+		return 0, 0, err
+	}
+	return cgroupMetricsForPID(pid)
+}
+
+// Logs returns log output for container; runc does not retain container
+// log output so this is unsupported
+func (r *RuncDriver) Logs(_ context.Context, _ Container) (io.ReadCloser, error) {
+	return nil, errors.New("not implemented")
+}
+
 // ProcNames returns the list of process names contributing to mem/cpu usage during overhead benchmark
 func (r *RuncDriver) ProcNames() []string {
 	return []string{}
@@ -149,14 +202,39 @@ func (r *RuncDriver) Info(ctx context.Context) (string, error) {
 
 // Create will create a container instance matching the specific needs
 // of a driver
-func (r *RuncDriver) Create(_ context.Context, name, image, _ string, detached bool, trace bool) (Container, error) {
-	return newRuncContainer(name, image, detached, trace), nil
+func (r *RuncDriver) Create(ctx context.Context, name, image string, _ []string, detached bool, trace bool) (Container, error) {
+	bundle := image
+	if r.bundleIsolation {
+		var err error
+		if bundle, err = r.isolatedBundle(ctx, name, image); err != nil {
+			return nil, err
+		}
+	}
+	return newRuncContainer(name, bundle, detached, trace), nil
+}
+
+// isolatedBundle returns the per-thread copy of the source bundle for
+// the named container, cloning it on first use so concurrent containers
+// don't share one mutable rootfs
+func (r *RuncDriver) isolatedBundle(ctx context.Context, name, src string) (string, error) {
+	key := bundleKey(name)
+	r.bundleMu.Lock()
+	defer r.bundleMu.Unlock()
+	if bundle, ok := r.bundles[key]; ok {
+		return bundle, nil
+	}
+	dst := "/tmp/bb-bundle-runc-" + key
+	if err := cloneBundle(ctx, src, dst); err != nil {
+		return "", err
+	}
+	r.bundles[key] = dst
+	return dst, nil
 }
 
 // Clean will clean the environment; removing any remaining containers in the runc metadata
 func (r *RuncDriver) Clean(ctx context.Context) error {
 	var tries int
-	out, err := utils.ExecCmd(ctx, r.runcBinary, "list")
+	out, err := utils.ExecCmd(ctx, r.runcBinary, r.globalOpts+"list")
 	if err != nil {
 		return fmt.Errorf("Error getting runc list output: (err: %v) output: %s", err, out)
 	}
@@ -183,16 +261,38 @@ func (r *RuncDriver) Clean(ctx context.Context) error {
 			}
 		}
 		tries++
-		out, err := utils.ExecCmd(ctx, r.runcBinary, "list")
+		out, err := utils.ExecCmd(ctx, r.runcBinary, r.globalOpts+"list")
 		if err != nil {
 			return fmt.Errorf("Error getting runc list output: %v", err)
 		}
 		containers = parseRuncList(out)
 	}
+	r.bundleMu.Lock()
+	for _, bundle := range r.bundles {
+		if err := os.RemoveAll(bundle); err != nil {
+			log.Warnf("Could not remove bundle copy %q: %v", bundle, err)
+		}
+	}
+	r.bundles = map[string]string{}
+	r.bundleMu.Unlock()
 	log.Infof("runc cleanup complete.")
 	return nil
 }
 
+// AuditLeaks lists containers still present in the runc state
+// directory, for the post-run leak audit
+func (r *RuncDriver) AuditLeaks(ctx context.Context) ([]string, error) {
+	out, err := utils.ExecCmd(ctx, r.runcBinary, r.globalOpts+"list")
+	if err != nil {
+		return nil, err
+	}
+	var leaks []string
+	for _, ctr := range parseRuncList(out) {
+		leaks = append(leaks, fmt.Sprintf("container %s (%s)", ctr.Name(), ctr.State()))
+	}
+	return leaks, nil
+}
+
 // Run will execute a container using the driver. Note that if the container is specified to
 // run detached, but the config.json for the bundle specifies a "tty" allocation, this
 // runc invocation will fail due to the fact we cannot detach without providing a "--console"
@@ -211,29 +311,45 @@ func (r *RuncDriver) Run(ctx context.Context, ctr Container) (string, time.Durat
 		trace = fmt.Sprintf("--trace /tmp/%s.trace ", ctr.Name())
 	}
 
-	args := fmt.Sprintf("%srun %s --bundle %s %s", trace, detached, ctr.Image(), ctr.Name())
+	args := fmt.Sprintf("%s%srun %s --bundle %s %s", r.globalOpts, trace, detached, ctr.Image(), ctr.Name())
 	// the "NoOut" variant of ExecTimedCmd ignores stdin/out/err (sets them to /dev/null)
 	return utils.ExecTimedCmdNoOut(ctx, r.runcBinary, args)
 }
 
 // Stop will stop/kill a container
 func (r *RuncDriver) Stop(ctx context.Context, ctr Container) (string, time.Duration, error) {
-	return utils.ExecTimedCmd(ctx, r.runcBinary, "kill "+ctr.Name()+" KILL")
+	return utils.ExecTimedCmd(ctx, r.runcBinary, r.globalOpts+"kill "+ctr.Name()+" "+r.stopSignal)
 }
 
 // Remove will remove a container
 func (r *RuncDriver) Remove(ctx context.Context, ctr Container) (string, time.Duration, error) {
-	return utils.ExecTimedCmd(ctx, r.runcBinary, "delete "+ctr.Name())
+	return utils.ExecTimedCmd(ctx, r.runcBinary, r.globalOpts+"delete "+ctr.Name())
+}
+
+// Exec will execute a command inside a running container
+func (r *RuncDriver) Exec(ctx context.Context, ctr Container, cmd string) (string, time.Duration, error) {
+	return utils.ExecTimedCmd(ctx, r.runcBinary, r.globalOpts+"exec "+ctr.Name()+" "+cmd)
+}
+
+// Checkpoint will checkpoint a running container's state via CRIU
+func (r *RuncDriver) Checkpoint(ctx context.Context, ctr Container) (string, time.Duration, error) {
+	return utils.ExecTimedCmd(ctx, r.runcBinary, r.globalOpts+"checkpoint --image-path "+criuImagePath(ctr.Name())+" "+ctr.Name())
+}
+
+// Restore will restore a previously checkpointed container
+func (r *RuncDriver) Restore(ctx context.Context, ctr Container) (string, time.Duration, error) {
+	args := fmt.Sprintf("%srestore --detach --image-path %s --bundle %s %s", r.globalOpts, criuImagePath(ctr.Name()), ctr.Image(), ctr.Name())
+	return utils.ExecTimedCmdNoOut(ctx, r.runcBinary, args)
 }
 
 // Pause will pause a container
 func (r *RuncDriver) Pause(ctx context.Context, ctr Container) (string, time.Duration, error) {
-	return utils.ExecTimedCmd(ctx, r.runcBinary, "pause "+ctr.Name())
+	return utils.ExecTimedCmd(ctx, r.runcBinary, r.globalOpts+"pause "+ctr.Name())
 }
 
 // Unpause will unpause/resume a container
 func (r *RuncDriver) Unpause(ctx context.Context, ctr Container) (string, time.Duration, error) {
-	return utils.ExecTimedCmd(ctx, r.runcBinary, "resume "+ctr.Name())
+	return utils.ExecTimedCmd(ctx, r.runcBinary, r.globalOpts+"resume "+ctr.Name())
 }
 
 // take the output of "runc list" and parse into container instances
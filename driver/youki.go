@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/estesp/bucketbench/utils"
@@ -20,6 +22,12 @@ const defaultYoukiBinary = "youki"
 // At this time there is no understood use case for multi-threaded use of this implementation.
 type YoukiDriver struct {
 	youkiBinary string
+	stopSignal  string
+	globalOpts  string
+
+	bundleIsolation bool
+	bundleMu        sync.Mutex
+	bundles         map[string]string
 }
 
 // YoukiContainer is an implementation of the container metadata needed for youki
@@ -33,7 +41,8 @@ type YoukiContainer struct {
 }
 
 // NewYoukiDriver creates an instance of the youki driver, providing a path to youki
-func NewYoukiDriver(binaryPath string) (Driver, error) {
+func NewYoukiDriver(config *Config) (Driver, error) {
+	binaryPath := config.Path
 	if binaryPath == "" {
 		binaryPath = defaultYoukiBinary
 	}
@@ -42,7 +51,11 @@ func NewYoukiDriver(binaryPath string) (Driver, error) {
 		return &YoukiDriver{}, err
 	}
 	driver := &YoukiDriver{
-		youkiBinary: resolvedBinPath,
+		youkiBinary:     resolvedBinPath,
+		stopSignal:      stopSignalName(config.StopSignal, "KILL"),
+		globalOpts:      runtimeGlobalOpts(config),
+		bundleIsolation: config.BundleIsolation,
+		bundles:         map[string]string{},
 	}
 	return driver, nil
 }
@@ -80,8 +93,8 @@ func (c *YoukiContainer) Image() string {
 
 // Command is not implemented for the youki driver type
 // as the command is embedded in the config.json of the rootfs
-func (c *YoukiContainer) Command() string {
-	return ""
+func (c *YoukiContainer) Command() []string {
+	return nil
 }
 
 // Pid returns the process ID in cases where this container instance is
@@ -101,6 +114,13 @@ func (c *YoukiContainer) GetPodID() string {
 	return ""
 }
 
+// Capabilities reports the optional operations this driver
+// supports: youki retains no log output and has no stats stream or
+// checkpoint/restore support
+func (r *YoukiDriver) Capabilities() Capabilities {
+	return Capabilities{Pause: true, Exec: true, Wait: true, Logs: false, Metrics: false, Checkpoint: false}
+}
+
 // Type returns a driver.Type to indentify the driver implementation
 func (r *YoukiDriver) Type() Type {
 	return Youki
@@ -122,9 +142,21 @@ func (r *YoukiDriver) PID() (int, error) {
 	return 0, errors.New("not implemented")
 }
 
-// Wait will block until container stop
-func (r *YoukiDriver) Wait(_ context.Context, _ Container) (string, time.Duration, error) {
-	return "", 0, errors.New("not implemented")
+// Wait blocks until the container reports a stopped state, polling the
+// runtime's state query; a state error after the container exits means it
+// was already deleted, which also ends the wait
+func (r *YoukiDriver) Wait(ctx context.Context, ctr Container) (string, time.Duration, error) {
+	start := time.Now()
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", 0, err
+		}
+		out, err := utils.ExecCmd(ctx, r.youkiBinary, r.globalOpts+"state "+ctr.Name())
+		if err != nil || strings.Contains(out, "stopped") {
+			return out, time.Since(start), nil
+		}
+		time.Sleep(waitPollInterval)
+	}
 }
 
 // Stats returns stats data from daemon for container
@@ -132,6 +164,27 @@ func (r *YoukiDriver) Stats(_ context.Context, _ Container) (io.ReadCloser, erro
 	return nil, errors.New("not implemented")
 }
 
+// ContainerMetrics takes a one-shot measurement of the container's
+// memory and CPU usage by reading its cgroup directly, since youki
+// has no daemon to query for stats
+func (r *YoukiDriver) ContainerMetrics(ctx context.Context, ctr Container) (uint64, uint64, error) {
+	out, err := utils.ExecCmd(ctx, r.youkiBinary, r.globalOpts+"state "+ctr.Name())
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get youki state for %q: %v", ctr.Name(), err)
+	}
+	pid, err := statePid(out)
+	if err != nil {
+		return 0, 0, err
+	}
+	return cgroupMetricsForPID(pid)
+}
+
+// Logs returns log output for container; youki does not retain container
+// log output so this is unsupported
+func (r *YoukiDriver) Logs(_ context.Context, _ Container) (io.ReadCloser, error) {
+	return nil, errors.New("not implemented")
+}
+
 // ProcNames returns the list of process names contributing to mem/cpu usage during overhead benchmark
 func (r *YoukiDriver) ProcNames() []string {
 	return []string{}
@@ -149,14 +202,39 @@ func (r *YoukiDriver) Info(ctx context.Context) (string, error) {
 
 // Create will create a container instance matching the specific needs
 // of a driver
-func (r *YoukiDriver) Create(_ context.Context, name, image, _ string, _ bool, _ bool) (Container, error) {
-	return newYoukiContainer(name, image, false, false), nil
+func (r *YoukiDriver) Create(ctx context.Context, name, image string, _ []string, _ bool, _ bool) (Container, error) {
+	bundle := image
+	if r.bundleIsolation {
+		var err error
+		if bundle, err = r.isolatedBundle(ctx, name, image); err != nil {
+			return nil, err
+		}
+	}
+	return newYoukiContainer(name, bundle, false, false), nil
+}
+
+// isolatedBundle returns the per-thread copy of the source bundle for
+// the named container, cloning it on first use so concurrent containers
+// don't share one mutable rootfs
+func (r *YoukiDriver) isolatedBundle(ctx context.Context, name, src string) (string, error) {
+	key := bundleKey(name)
+	r.bundleMu.Lock()
+	defer r.bundleMu.Unlock()
+	if bundle, ok := r.bundles[key]; ok {
+		return bundle, nil
+	}
+	dst := "/tmp/bb-bundle-youki-" + key
+	if err := cloneBundle(ctx, src, dst); err != nil {
+		return "", err
+	}
+	r.bundles[key] = dst
+	return dst, nil
 }
 
 // Clean will clean the environment; removing any remaining containers in the youki metadata
 func (r *YoukiDriver) Clean(ctx context.Context) error {
 	var tries int
-	out, err := utils.ExecCmd(ctx, r.youkiBinary, "list")
+	out, err := utils.ExecCmd(ctx, r.youkiBinary, r.globalOpts+"list")
 	if err != nil {
 		return fmt.Errorf("Error getting youki list output: (err: %v) output: %s", err, out)
 	}
@@ -183,42 +261,79 @@ func (r *YoukiDriver) Clean(ctx context.Context) error {
 			}
 		}
 		tries++
-		out, err := utils.ExecCmd(ctx, r.youkiBinary, "list")
+		out, err := utils.ExecCmd(ctx, r.youkiBinary, r.globalOpts+"list")
 		if err != nil {
 			return fmt.Errorf("Error getting youki list output: %v", err)
 		}
 		containers = parseYoukiList(out)
 	}
+	r.bundleMu.Lock()
+	for _, bundle := range r.bundles {
+		if err := os.RemoveAll(bundle); err != nil {
+			log.Warnf("Could not remove bundle copy %q: %v", bundle, err)
+		}
+	}
+	r.bundles = map[string]string{}
+	r.bundleMu.Unlock()
 	log.Infof("youki cleanup complete.")
 	return nil
 }
 
+// AuditLeaks lists containers still present in the youki state
+// directory, for the post-run leak audit
+func (r *YoukiDriver) AuditLeaks(ctx context.Context) ([]string, error) {
+	out, err := utils.ExecCmd(ctx, r.youkiBinary, r.globalOpts+"list")
+	if err != nil {
+		return nil, err
+	}
+	var leaks []string
+	for _, ctr := range parseYoukiList(out) {
+		leaks = append(leaks, fmt.Sprintf("container %s (%s)", ctr.Name(), ctr.State()))
+	}
+	return leaks, nil
+}
+
 // Run will execute a container using the driver.Youki automatically uses detach mode.
 func (r *YoukiDriver) Run(ctx context.Context, ctr Container) (string, time.Duration, error) {
 
-	args := fmt.Sprintf("run --bundle %s %s", ctr.Image(), ctr.Name())
+	args := fmt.Sprintf("%srun --bundle %s %s", r.globalOpts, ctr.Image(), ctr.Name())
 	// the "NoOut" variant of ExecTimedCmd ignores stdin/out/err (sets them to /dev/null)
 	return utils.ExecTimedCmdNoOut(ctx, r.youkiBinary, args)
 }
 
 // Stop will stop/kill a container
 func (r *YoukiDriver) Stop(ctx context.Context, ctr Container) (string, time.Duration, error) {
-	return utils.ExecTimedCmd(ctx, r.youkiBinary, "kill "+ctr.Name()+" KILL")
+	return utils.ExecTimedCmd(ctx, r.youkiBinary, r.globalOpts+"kill "+ctr.Name()+" "+r.stopSignal)
 }
 
 // Remove will remove a container
 func (r *YoukiDriver) Remove(ctx context.Context, ctr Container) (string, time.Duration, error) {
-	return utils.ExecTimedCmd(ctx, r.youkiBinary, "delete "+ctr.Name())
+	return utils.ExecTimedCmd(ctx, r.youkiBinary, r.globalOpts+"delete "+ctr.Name())
+}
+
+// Exec will execute a command inside a running container
+func (r *YoukiDriver) Exec(ctx context.Context, ctr Container, cmd string) (string, time.Duration, error) {
+	return utils.ExecTimedCmd(ctx, r.youkiBinary, r.globalOpts+"exec "+ctr.Name()+" "+cmd)
+}
+
+// Checkpoint will checkpoint a running container's state via CRIU
+func (r *YoukiDriver) Checkpoint(_ context.Context, _ Container) (string, time.Duration, error) {
+	return "", 0, errors.New("not implemented")
+}
+
+// Restore will restore a previously checkpointed container
+func (r *YoukiDriver) Restore(_ context.Context, _ Container) (string, time.Duration, error) {
+	return "", 0, errors.New("not implemented")
 }
 
 // Pause will pause a container
 func (r *YoukiDriver) Pause(ctx context.Context, ctr Container) (string, time.Duration, error) {
-	return utils.ExecTimedCmd(ctx, r.youkiBinary, "pause "+ctr.Name())
+	return utils.ExecTimedCmd(ctx, r.youkiBinary, r.globalOpts+"pause "+ctr.Name())
 }
 
 // Unpause will unpause/resume a container
 func (r *YoukiDriver) Unpause(ctx context.Context, ctr Container) (string, time.Duration, error) {
-	return utils.ExecTimedCmd(ctx, r.youkiBinary, "resume "+ctr.Name())
+	return utils.ExecTimedCmd(ctx, r.youkiBinary, r.globalOpts+"resume "+ctr.Name())
 }
 
 // take the output of "youki list" and parse into container instances
@@ -0,0 +1,10 @@
+//go:build linux
+// +build linux
+
+package stats
+
+func hostCapabilities() Capabilities {
+	return Capabilities{
+		CGroups: true,
+	}
+}
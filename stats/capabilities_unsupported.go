@@ -0,0 +1,8 @@
+//go:build !linux
+// +build !linux
+
+package stats
+
+func hostCapabilities() Capabilities {
+	return Capabilities{}
+}
@@ -1,30 +1,49 @@
-//go:build !windows
-// +build !windows
+//go:build linux
+// +build linux
 
 package stats
 
 import (
+	"strings"
 	"time"
 
 	"github.com/containerd/cgroups"
+	v1 "github.com/containerd/cgroups/stats/v1"
+	"github.com/estesp/bucketbench/utils"
 	"github.com/pkg/errors"
 )
 
 // CGroupsSampler represents Linux cgroups sampler
 type CGroupsSampler struct {
 	control      cgroups.Cgroup
+	netPID       int
 	lastCPUUsage uint64
 	lastCPUTime  time.Time
 }
 
-// NewCGroupsSampler creates a stats sampler from existing control group
-func NewCGroupsSampler(path string) (*CGroupsSampler, error) {
+// NewCGroupsSampler creates a stats sampler from existing control group;
+// netPID, when non-zero, names a process whose network namespace counters
+// are sampled alongside the cgroup metrics
+func NewCGroupsSampler(path string, netPID int) (*CGroupsSampler, error) {
 	control, err := cgroups.Load(reportControllers, cgroups.StaticPath(path))
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to load cgroup: '%s'", path)
 	}
 
-	return &CGroupsSampler{control: control}, nil
+	return &CGroupsSampler{control: control, netPID: netPID}, nil
+}
+
+// blkioTotals sums per-device blkio entries into read/write totals
+func blkioTotals(entries []*v1.BlkIOEntry) (read, write uint64) {
+	for _, entry := range entries {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			read += entry.Value
+		case "write":
+			write += entry.Value
+		}
+	}
+	return
 }
 
 // reportControllers returns v1 controllers only required for measuring resource usage
@@ -36,7 +55,7 @@ func reportControllers() ([]cgroups.Subsystem, error) {
 
 	var out []cgroups.Subsystem
 	for _, sub := range v1 {
-		if sub.Name() == cgroups.Memory || sub.Name() == cgroups.Cpuacct {
+		if sub.Name() == cgroups.Memory || sub.Name() == cgroups.Cpuacct || sub.Name() == cgroups.Blkio {
 			out = append(out, sub)
 		}
 	}
@@ -65,8 +84,29 @@ func (s *CGroupsSampler) Query() (*ProcMetrics, error) {
 	s.lastCPUUsage = cpu
 	s.lastCPUTime = now
 
-	return &ProcMetrics{
+	sample := &ProcMetrics{
 		Mem: mem,
 		CPU: cpuUsage,
-	}, nil
+	}
+
+	if metrics.Blkio != nil {
+		sample.BlkReadBytes, sample.BlkWriteBytes = blkioTotals(metrics.Blkio.IoServiceBytesRecursive)
+		sample.BlkReadOps, sample.BlkWriteOps = blkioTotals(metrics.Blkio.IoServicedRecursive)
+	}
+
+	if s.netPID > 0 {
+		rxBytes, txBytes, rxPackets, txPackets, err := utils.NetIOForPID(s.netPID)
+		if err == nil {
+			sample.NetRxBytes, sample.NetTxBytes = rxBytes, txBytes
+			sample.NetRxPackets, sample.NetTxPackets = rxPackets, txPackets
+		}
+
+		// fd/thread counts come from the daemon process itself; the
+		// cgroup only tracks an aggregate pid count
+		if fds, threads, err := utils.FDThreadsForPID(s.netPID); err == nil {
+			sample.FDs, sample.Threads = fds, threads
+		}
+	}
+
+	return sample, nil
 }
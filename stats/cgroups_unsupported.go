@@ -0,0 +1,18 @@
+//go:build !linux
+// +build !linux
+
+package stats
+
+// CGroupsSampler is a no-op placeholder on operating systems without
+// control group support; NewSampler never selects it on these platforms
+type CGroupsSampler struct{}
+
+// NewCGroupsSampler creates a stats sampler from existing control group
+func NewCGroupsSampler(path string, netPID int) (*CGroupsSampler, error) {
+	return nil, ErrNotSupported
+}
+
+// Query gets a process metrics from control cgroup
+func (s *CGroupsSampler) Query() (*ProcMetrics, error) {
+	return nil, ErrNotSupported
+}
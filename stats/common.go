@@ -2,12 +2,47 @@ package stats
 
 import (
 	"runtime"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
+// ErrNotSupported is returned by samplers for metrics which are unavailable
+// on the current operating system
+var ErrNotSupported = errors.New("metric unavailable on this OS")
+
 // ProcMetrics represents stats sample from daemon
 type ProcMetrics struct {
 	Mem uint64
 	CPU float64
+	// cumulative block I/O counters for the daemon (and children/shims);
+	// deltas across a run expose disk churn differences between snapshotters
+	BlkReadBytes  uint64
+	BlkWriteBytes uint64
+	BlkReadOps    uint64
+	BlkWriteOps   uint64
+	// cumulative network counters from the daemon's network namespace;
+	// deltas surface registry traffic and API chatter during a run
+	NetRxBytes   uint64
+	NetTxBytes   uint64
+	NetRxPackets uint64
+	NetTxPackets uint64
+	// current open file descriptor and OS thread counts for the daemon
+	// processes; fd leaks under churn show up as a climbing max
+	FDs     uint64
+	Threads uint64
+}
+
+// Capabilities describes which platform-specific stats features are usable
+// on the current operating system
+type Capabilities struct {
+	// CGroups reports whether control group based daemon sampling is available
+	CGroups bool
+}
+
+// HostCapabilities returns the stats features available on this host OS
+func HostCapabilities() Capabilities {
+	return hostCapabilities()
 }
 
 // Process represents an interfaces of a daemon to be sampled
@@ -19,18 +54,38 @@ type Process interface {
 	ProcNames() []string
 }
 
+// DaemonCGrouper is implemented by drivers which know the control group
+// their daemon (and its child shims) runs under, so overhead sampling can
+// use the cgroup hierarchy instead of walking processes by name
+type DaemonCGrouper interface {
+	// DaemonCGroupPath returns the daemon's cgroup path relative to the
+	// cgroup root (e.g. a systemd service slice path)
+	DaemonCGroupPath() string
+}
+
 // Sampler represents an interface of a sampler
 type Sampler interface {
 	// Query gets a process metrics (cpu and memory usage) or error
 	Query() (*ProcMetrics, error)
 }
 
-// NewSampler creates a CGroups stats sampler on Linux for a given 'cgroupPath' and
-// fallbacks to psutils implementation on other operating systems
-func NewSampler(proc Process, cgroupPath string) (Sampler, error) {
-	if runtime.GOOS == "linux" && cgroupPath != "" {
-		return NewCGroupsSampler(cgroupPath)
+// NewSampler creates a CGroups stats sampler for a given 'cgroupPath' where
+// the host OS supports control groups, and falls back to the psutils
+// implementation elsewhere (logging the degradation rather than erroring on
+// every sample)
+func NewSampler(proc Process, cgroupPath, memMetric string) (Sampler, error) {
+	if cgroupPath != "" {
+		if HostCapabilities().CGroups {
+			// cgroups don't account network traffic, so the daemon PID
+			// (when known) is kept for reading netns counters per sample
+			netPID := 0
+			if pid, err := proc.PID(); err == nil {
+				netPID = pid
+			}
+			return NewCGroupsSampler(cgroupPath, netPID)
+		}
+		log.Warnf("cgroup-based sampling unavailable on %s; falling back to process sampling", runtime.GOOS)
 	}
 
-	return NewPSUtilSampler(proc)
+	return NewPSUtilSampler(proc, memMetric)
 }
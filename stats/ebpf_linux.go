@@ -0,0 +1,99 @@
+//go:build linux
+// +build linux
+
+package stats
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// tracedSyscalls are the syscall tracepoints counted by the collector;
+// clone/execve show process churn from shims and hooks, while the rest
+// capture the bulk I/O and synchronization work a runtime does per operation
+var tracedSyscalls = []string{"clone", "execve", "openat", "read", "write", "futex"}
+
+// SyscallCollector counts selected syscalls issued by the daemon and shim
+// processes via an eBPF tracepoint program driven by the bpftrace binary;
+// counts give insight into why one runtime is slower, not just by how much
+type SyscallCollector struct {
+	cmd    *exec.Cmd
+	output bytes.Buffer
+}
+
+// NewSyscallCollector builds a collector tracing syscalls from processes
+// whose names match the driver's process list; requires the bpftrace binary
+// and sufficient privilege to load eBPF programs
+func NewSyscallCollector(procNames []string) (*SyscallCollector, error) {
+	if len(procNames) == 0 {
+		return nil, errors.New("no process names to trace")
+	}
+	binary, err := exec.LookPath("bpftrace")
+	if err != nil {
+		return nil, errors.Wrap(err, "bpftrace binary not found; syscall tracing unavailable")
+	}
+
+	// bpftrace's comm builtin is limited to 16 bytes, matching the kernel's
+	// TASK_COMM_LEN truncation of process names
+	var filters []string
+	for _, name := range procNames {
+		if len(name) > 15 {
+			name = name[:15]
+		}
+		filters = append(filters, fmt.Sprintf("comm==%q", name))
+	}
+	filter := strings.Join(filters, "||")
+
+	var script strings.Builder
+	for _, name := range tracedSyscalls {
+		fmt.Fprintf(&script, "tracepoint:syscalls:sys_enter_%s /%s/ { @[%q] = count(); } ", name, filter, name)
+	}
+
+	collector := &SyscallCollector{
+		cmd: exec.Command(binary, "-e", script.String()),
+	}
+	collector.cmd.Stdout = &collector.output
+	return collector, nil
+}
+
+// Start loads the tracepoint program and begins counting
+func (c *SyscallCollector) Start() error {
+	return errors.Wrap(c.cmd.Start(), "failed to start bpftrace")
+}
+
+// Stop detaches the tracepoint program and returns the per-syscall counts
+// accumulated since Start
+func (c *SyscallCollector) Stop() (map[string]uint64, error) {
+	// bpftrace prints its maps on SIGINT before exiting
+	if err := c.cmd.Process.Signal(syscall.SIGINT); err != nil {
+		return nil, errors.Wrap(err, "failed to signal bpftrace")
+	}
+	if err := c.cmd.Wait(); err != nil {
+		return nil, errors.Wrapf(err, "bpftrace exited with error: %s", c.output.String())
+	}
+
+	// map entries are printed one per line as `@[name]: count`
+	counts := make(map[string]uint64)
+	for _, line := range strings.Split(c.output.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "@[") {
+			continue
+		}
+		end := strings.Index(line, "]:")
+		if end < 0 {
+			continue
+		}
+		value, err := strconv.ParseUint(strings.TrimSpace(line[end+2:]), 10, 64)
+		if err != nil {
+			continue
+		}
+		counts[strings.TrimPrefix(line[:end], "@[")] = value
+	}
+	return counts, nil
+}
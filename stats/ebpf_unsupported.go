@@ -0,0 +1,23 @@
+//go:build !linux
+// +build !linux
+
+package stats
+
+// SyscallCollector is a no-op placeholder on operating systems without
+// eBPF tracepoint support
+type SyscallCollector struct{}
+
+// NewSyscallCollector creates an eBPF syscall-counting collector
+func NewSyscallCollector(procNames []string) (*SyscallCollector, error) {
+	return nil, ErrNotSupported
+}
+
+// Start loads the tracepoint program and begins counting
+func (c *SyscallCollector) Start() error {
+	return ErrNotSupported
+}
+
+// Stop detaches the tracepoint program and returns the per-syscall counts
+func (c *SyscallCollector) Stop() (map[string]uint64, error) {
+	return nil, ErrNotSupported
+}
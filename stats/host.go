@@ -0,0 +1,97 @@
+package stats
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// HostMetrics captures system-wide conditions around a benchmark run so
+// readers can judge whether the host itself was saturated while the
+// numbers were collected
+type HostMetrics struct {
+	// 1/5/15 minute load averages
+	Load1  float64
+	Load5  float64
+	Load15 float64
+	// "some" avg10 pressure-stall percentages from the PSI interface
+	// (zero on kernels without CONFIG_PSI)
+	CPUPressure    float64
+	MemoryPressure float64
+	IOPressure     float64
+	// cumulative context switches since boot; the delta across a run
+	// gives the switch rate under load
+	ContextSwitches uint64
+}
+
+// SampleHost reads current host-level metrics from /proc; on operating
+// systems without procfs an error is returned and host capture is skipped
+func SampleHost() (*HostMetrics, error) {
+	metrics := &HostMetrics{}
+
+	data, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read load average")
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return nil, errors.Errorf("unexpected loadavg format: %q", string(data))
+	}
+	metrics.Load1, _ = strconv.ParseFloat(fields[0], 64)
+	metrics.Load5, _ = strconv.ParseFloat(fields[1], 64)
+	metrics.Load15, _ = strconv.ParseFloat(fields[2], 64)
+
+	// PSI files are optional; leave pressure values at zero when absent
+	metrics.CPUPressure = readPressure("/proc/pressure/cpu")
+	metrics.MemoryPressure = readPressure("/proc/pressure/memory")
+	metrics.IOPressure = readPressure("/proc/pressure/io")
+
+	metrics.ContextSwitches = readContextSwitches()
+
+	return metrics, nil
+}
+
+// readPressure parses the "some" avg10 value from a PSI pressure file,
+// returning zero when the file or field is unavailable
+func readPressure(path string) float64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "some") {
+			continue
+		}
+		for _, field := range strings.Fields(line) {
+			if strings.HasPrefix(field, "avg10=") {
+				value, err := strconv.ParseFloat(strings.TrimPrefix(field, "avg10="), 64)
+				if err == nil {
+					return value
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// readContextSwitches returns the cumulative context-switch count from
+// /proc/stat, or zero when unavailable
+func readContextSwitches() uint64 {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return 0
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "ctxt ") {
+			value, err := strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "ctxt ")), 10, 64)
+			if err == nil {
+				return value
+			}
+		}
+	}
+	return 0
+}
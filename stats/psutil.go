@@ -3,17 +3,32 @@ package stats
 import (
 	"github.com/estesp/bucketbench/utils"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 const bytesInMiB = 1024 * 1024
 
+// memory accounting modes for process-tree sampling; RSS is the historical
+// default but double-counts pages shared across shim processes
+const (
+	// MemMetricRSS sums resident set sizes across the process tree
+	MemMetricRSS = "rss"
+	// MemMetricPSS sums proportional set sizes, dividing shared pages
+	// among their sharers
+	MemMetricPSS = "pss"
+	// MemMetricUSS sums only pages private to each process
+	MemMetricUSS = "uss"
+)
+
 // PSUtilSampler represents ps util sampler
 type PSUtilSampler struct {
-	proc *utils.Proc
+	proc      *utils.Proc
+	memMetric string
 }
 
-// NewPSUtilSampler creates a new ps util sampler
-func NewPSUtilSampler(proc Process) (*PSUtilSampler, error) {
+// NewPSUtilSampler creates a new ps util sampler using the given memory
+// accounting mode ("rss" when empty)
+func NewPSUtilSampler(proc Process, memMetric string) (*PSUtilSampler, error) {
 	pid, err := proc.PID()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get root process pid")
@@ -24,12 +39,23 @@ func NewPSUtilSampler(proc Process) (*PSUtilSampler, error) {
 		return nil, errors.Wrapf(err, "failed to create process from pid: %d", pid)
 	}
 
-	return &PSUtilSampler{daemonProc}, nil
+	return &PSUtilSampler{proc: daemonProc, memMetric: memMetric}, nil
 }
 
 // Query gets a process metrics
 func (s *PSUtilSampler) Query() (*ProcMetrics, error) {
-	mem, memErr := s.proc.Mem()
+	var (
+		mem    uint64
+		memErr error
+	)
+	switch s.memMetric {
+	case MemMetricPSS:
+		mem, memErr = s.proc.MemPSS()
+	case MemMetricUSS:
+		mem, memErr = s.proc.MemUSS()
+	default:
+		mem, memErr = s.proc.Mem()
+	}
 	if memErr != nil {
 		return nil, errors.Wrapf(memErr, "couldn't get mem info for proc: %d", s.proc.PID())
 	}
@@ -39,8 +65,36 @@ func (s *PSUtilSampler) Query() (*ProcMetrics, error) {
 		return nil, errors.Wrapf(cpuErr, "couldn't get cpu info for proc: %d", s.proc.PID())
 	}
 
-	return &ProcMetrics{
-		Mem: mem / bytesInMiB,
-		CPU: cpu,
-	}, nil
+	// I/O counters may be unreadable (permissions) on some hosts; report
+	// zeroes rather than failing the whole sample
+	readBytes, writeBytes, readOps, writeOps, ioErr := s.proc.IO()
+	if ioErr != nil {
+		log.WithError(ioErr).Debugf("couldn't get io counters for proc: %d", s.proc.PID())
+	}
+
+	sample := &ProcMetrics{
+		Mem:           mem / bytesInMiB,
+		CPU:           cpu,
+		BlkReadBytes:  readBytes,
+		BlkWriteBytes: writeBytes,
+		BlkReadOps:    readOps,
+		BlkWriteOps:   writeOps,
+	}
+
+	fds, threads, fdErr := s.proc.FDThreads()
+	if fdErr != nil {
+		log.WithError(fdErr).Debugf("couldn't get fd/thread counts for proc: %d", s.proc.PID())
+	} else {
+		sample.FDs, sample.Threads = fds, threads
+	}
+
+	rxBytes, txBytes, rxPackets, txPackets, netErr := utils.NetIOForPID(s.proc.PID())
+	if netErr != nil {
+		log.WithError(netErr).Debugf("couldn't get net counters for proc: %d", s.proc.PID())
+	} else {
+		sample.NetRxBytes, sample.NetTxBytes = rxBytes, txBytes
+		sample.NetRxPackets, sample.NetTxPackets = rxPackets, txPackets
+	}
+
+	return sample, nil
 }
@@ -0,0 +1,20 @@
+//go:build linux
+// +build linux
+
+package utils
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// PinToCPUs restricts the calling process — all current and future
+// threads, so every worker goroutine inherits it — to the given CPUs,
+// keeping the load generator off the CPUs the daemon under test is using
+func PinToCPUs(cpus []int) error {
+	var set unix.CPUSet
+	set.Zero()
+	for _, cpu := range cpus {
+		set.Set(cpu)
+	}
+	return unix.SchedSetaffinity(0, &set)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package utils
+
+import (
+	"github.com/pkg/errors"
+)
+
+// PinToCPUs is only implemented on Linux
+func PinToCPUs(cpus []int) error {
+	return errors.New("CPU pinning is not supported on this platform")
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseCPUSet parses a kernel-style CPU list specification such as
+// "0-3,8" into the individual CPU numbers it covers
+func ParseCPUSet(spec string) ([]int, error) {
+	var cpus []int
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if lo, hi, isRange := strings.Cut(part, "-"); isRange {
+			start, err := strconv.Atoi(lo)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CPU range %q: %v", part, err)
+			}
+			end, err := strconv.Atoi(hi)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CPU range %q: %v", part, err)
+			}
+			if start < 0 || end < start {
+				return nil, fmt.Errorf("invalid CPU range %q", part)
+			}
+			for cpu := start; cpu <= end; cpu++ {
+				cpus = append(cpus, cpu)
+			}
+			continue
+		}
+		cpu, err := strconv.Atoi(part)
+		if err != nil || cpu < 0 {
+			return nil, fmt.Errorf("invalid CPU number %q", part)
+		}
+		cpus = append(cpus, cpu)
+	}
+	if len(cpus) == 0 {
+		return nil, fmt.Errorf("empty CPU list %q", spec)
+	}
+	return cpus, nil
+}
@@ -0,0 +1,18 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package utils
+
+import (
+	"syscall"
+)
+
+// DiskFree returns the bytes available to unprivileged users on the
+// filesystem holding the given path
+func DiskFree(path string) (uint64, error) {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(path, &fs); err != nil {
+		return 0, err
+	}
+	return uint64(fs.Bavail) * uint64(fs.Bsize), nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package utils
+
+import (
+	"github.com/pkg/errors"
+)
+
+// DiskFree returns the bytes available on the filesystem holding the
+// given path; only implemented for unix-like hosts
+func DiskFree(path string) (uint64, error) {
+	return 0, errors.New("disk space query not supported on this platform")
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"os/exec"
 	"path/filepath"
@@ -24,15 +25,82 @@ func ResolveBinary(binname string) (string, error) {
 	return resolvedPath, nil
 }
 
+// SplitShellWords splits a command-line string into words following
+// shell-like rules: runs of whitespace separate words, single and double
+// quotes group characters (including spaces) into one word, and a backslash
+// escapes the next character outside single quotes. It exists so arguments
+// with spaces survive the trip through the CLI drivers' string-based exec
+// helpers.
+func SplitShellWords(input string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range input {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash in command %q", input)
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in command %q", quote, input)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
 // ExecTimedCmdNoOut executes a command and returns any errors, but ignores output
 // This function also times the command and returns the elapsed milliseconds
 func ExecTimedCmdNoOut(ctx context.Context, cmd, args string) (string, time.Duration, error) {
+	argv, err := SplitShellWords(args)
+	if err != nil {
+		return "", 0, err
+	}
 	start := time.Now()
-	execCmd := exec.CommandContext(ctx, cmd, strings.Split(args, " ")...)
+	execCmd := exec.CommandContext(ctx, cmd, argv...)
 	execCmd.Stdin = nil
 	execCmd.Stdout = nil
 	execCmd.Stderr = nil
-	err := execCmd.Run()
+	err = execCmd.Run()
 	elapsed := time.Since(start)
 	return "", elapsed, errors.Wrapf(err, "exec failed: %s %s", cmd, args)
 }
@@ -40,8 +108,12 @@ func ExecTimedCmdNoOut(ctx context.Context, cmd, args string) (string, time.Dura
 // ExecTimedCmd executes a command and returns the combined err/out output and any errors
 // This function also times the command and returns the elapsed milliseconds
 func ExecTimedCmd(ctx context.Context, cmd, args string) (string, time.Duration, error) {
+	argv, err := SplitShellWords(args)
+	if err != nil {
+		return "", 0, err
+	}
 	start := time.Now()
-	execCmd := exec.CommandContext(ctx, cmd, strings.Split(args, " ")...)
+	execCmd := exec.CommandContext(ctx, cmd, argv...)
 	out, err := execCmd.CombinedOutput()
 	elapsed := time.Since(start)
 	return string(out), elapsed, errors.Wrapf(err, "exec failed: %s %s", cmd, args)
@@ -49,7 +121,11 @@ func ExecTimedCmd(ctx context.Context, cmd, args string) (string, time.Duration,
 
 // ExecCmd executes a command and returns the combined err/out output and any errors
 func ExecCmd(ctx context.Context, cmd, args string) (string, error) {
-	execCmd := exec.CommandContext(ctx, cmd, strings.Split(args, " ")...)
+	argv, err := SplitShellWords(args)
+	if err != nil {
+		return "", err
+	}
+	execCmd := exec.CommandContext(ctx, cmd, argv...)
 	out, err := execCmd.CombinedOutput()
 	return string(out), errors.Wrapf(err, "exec failed: %s %s", cmd, args)
 }
@@ -64,9 +140,13 @@ func ExecShellCmd(ctx context.Context, cmd string) (string, error) {
 
 // ExecCmdStream executes a command and returns a Reader, which is useful for streaming
 func ExecCmdStream(ctx context.Context, cmd, args string) (io.ReadCloser, error) {
+	argv, err := SplitShellWords(args)
+	if err != nil {
+		return nil, err
+	}
 	reader, writer := io.Pipe()
 
-	execCmd := exec.CommandContext(ctx, cmd, strings.Split(args, " ")...)
+	execCmd := exec.CommandContext(ctx, cmd, argv...)
 	execCmd.Stdout = writer
 
 	if err := execCmd.Start(); err != nil {
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// firstProcessPollInterval is the delay between cgroup polls while waiting
+// for a container's init process to appear; kept short since the gap being
+// measured is often only a few milliseconds
+const firstProcessPollInterval = 2 * time.Millisecond
+
+// cgroupSearchPatterns are the cgroup directory layouts searched for a
+// container's cgroup, covering systemd scopes and cgroupfs paths on both
+// cgroup v1 and v2 hierarchies; %s is the container identifier
+var cgroupSearchPatterns = []string{
+	"/sys/fs/cgroup/system.slice/*%s*.scope",
+	"/sys/fs/cgroup/%s",
+	"/sys/fs/cgroup/*/%s",
+	"/sys/fs/cgroup/*/*/%s",
+	"/sys/fs/cgroup/systemd/*/%s",
+	"/sys/fs/cgroup/pids/*/%s",
+	"/sys/fs/cgroup/pids/*/*/%s",
+}
+
+// WaitForFirstProcess polls for a populated cgroup belonging to the given
+// container identifier and returns once its first process exists, exposing
+// the moment the init process actually executes rather than when the
+// engine's API call returned. The context bounds the wait.
+func WaitForFirstProcess(ctx context.Context, id string) error {
+	if _, err := os.Stat("/sys/fs/cgroup"); err != nil {
+		return errors.New("cgroup hierarchy not available on this host")
+	}
+	for {
+		if cgroupHasProcess(id) {
+			return nil
+		}
+		select {
+		case <-time.After(firstProcessPollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// cgroupHasProcess reports whether any cgroup directory matching the
+// container identifier has at least one member process
+func cgroupHasProcess(id string) bool {
+	for _, pattern := range cgroupSearchPatterns {
+		matches, err := filepath.Glob(fmt.Sprintf(pattern, id))
+		if err != nil {
+			continue
+		}
+		for _, dir := range matches {
+			procs, err := os.ReadFile(filepath.Join(dir, "cgroup.procs"))
+			if err == nil && len(bytes.TrimSpace(procs)) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
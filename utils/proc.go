@@ -84,6 +84,90 @@ func (p *Proc) CPU() (float64, error) {
 	return totalCPU, err
 }
 
+// IO returns cumulative read/write byte and operation counters for a
+// process and its children
+func (p *Proc) IO() (readBytes, writeBytes, readOps, writeOps uint64, err error) {
+	err = p.walkProcessTree(p.proc, func(p *process.Process) error {
+		counters, err := p.IOCounters()
+		if err != nil {
+			return err
+		}
+
+		readBytes += counters.ReadBytes
+		writeBytes += counters.WriteBytes
+		readOps += counters.ReadCount
+		writeOps += counters.WriteCount
+		return nil
+	})
+
+	return
+}
+
+// FDThreads returns the total open file descriptor and OS thread counts
+// for a process and its children
+func (p *Proc) FDThreads() (fds, threads uint64, err error) {
+	err = p.walkProcessTree(p.proc, func(p *process.Process) error {
+		numFDs, err := p.NumFDs()
+		if err != nil {
+			return err
+		}
+
+		numThreads, err := p.NumThreads()
+		if err != nil {
+			return err
+		}
+
+		fds += uint64(numFDs)
+		threads += uint64(numThreads)
+		return nil
+	})
+
+	return
+}
+
+// FDThreadsForPID returns open file descriptor and OS thread counts for a
+// single process by pid
+func FDThreadsForPID(pid int) (fds, threads uint64, err error) {
+	p, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return
+	}
+
+	numFDs, err := p.NumFDs()
+	if err != nil {
+		return
+	}
+
+	numThreads, err := p.NumThreads()
+	if err != nil {
+		return
+	}
+
+	return uint64(numFDs), uint64(numThreads), nil
+}
+
+// NetIOForPID returns cumulative network byte and packet counters from the
+// network namespace of the given process (per /proc/<pid>/net/dev)
+func NetIOForPID(pid int) (rxBytes, txBytes, rxPackets, txPackets uint64, err error) {
+	p, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return
+	}
+
+	counters, err := p.NetIOCounters(false)
+	if err != nil {
+		return
+	}
+
+	for _, c := range counters {
+		rxBytes += c.BytesRecv
+		txBytes += c.BytesSent
+		rxPackets += c.PacketsRecv
+		txPackets += c.PacketsSent
+	}
+	return
+}
+
 func (p *Proc) walkProcessTree(root *process.Process, callback func(*process.Process) error) error {
 	rootName, err := root.Name()
 	if err != nil {
@@ -0,0 +1,47 @@
+//go:build linux
+// +build linux
+
+package utils
+
+import (
+	"github.com/shirou/gopsutil/process"
+)
+
+// MemPSS returns the proportional set size of a process and its children in
+// bytes; shared pages are divided among sharers rather than counted once
+// per process, avoiding the RSS double-count across many shim processes
+func (p *Proc) MemPSS() (uint64, error) {
+	var totalMem uint64
+	err := p.walkProcessTree(p.proc, func(p *process.Process) error {
+		maps, err := p.MemoryMaps(true)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range *maps {
+			totalMem += m.Pss
+		}
+		return nil
+	})
+
+	return totalMem, err
+}
+
+// MemUSS returns the unique set size (private pages only) of a process and
+// its children in bytes
+func (p *Proc) MemUSS() (uint64, error) {
+	var totalMem uint64
+	err := p.walkProcessTree(p.proc, func(p *process.Process) error {
+		maps, err := p.MemoryMaps(true)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range *maps {
+			totalMem += m.PrivateClean + m.PrivateDirty
+		}
+		return nil
+	})
+
+	return totalMem, err
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+// +build !linux
+
+package utils
+
+import (
+	"github.com/pkg/errors"
+)
+
+// MemPSS returns the proportional set size of a process and its children in
+// bytes; smaps-based accounting is only available on Linux
+func (p *Proc) MemPSS() (uint64, error) {
+	return 0, errors.New("PSS accounting not supported on this platform")
+}
+
+// MemUSS returns the unique set size (private pages only) of a process and
+// its children in bytes; smaps-based accounting is only available on Linux
+func (p *Proc) MemUSS() (uint64, error) {
+	return 0, errors.New("USS accounting not supported on this platform")
+}
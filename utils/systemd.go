@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	systemd "github.com/coreos/go-systemd/v22/dbus"
+	"github.com/pkg/errors"
+)
+
+// SystemdController drives a daemon's systemd unit over the D-Bus API so
+// benchmarks can stop, start, or restart the engine between phases without
+// shelling out to systemctl
+type SystemdController struct {
+	conn *systemd.Conn
+	unit string
+}
+
+// NewSystemdController connects to the system bus for control of the named
+// unit; a ".service" suffix is appended when no unit type is given
+func NewSystemdController(ctx context.Context, unit string) (*SystemdController, error) {
+	if !strings.Contains(unit, ".") {
+		unit += ".service"
+	}
+	conn, err := systemd.NewWithContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to systemd over D-Bus")
+	}
+	return &SystemdController{conn: conn, unit: unit}, nil
+}
+
+// run enqueues one of the systemd job methods and waits for the job to
+// complete, returning the elapsed time
+func (c *SystemdController) run(ctx context.Context, op func(context.Context, string, string, chan<- string) (int, error)) (time.Duration, error) {
+	start := time.Now()
+	result := make(chan string, 1)
+	if _, err := op(ctx, c.unit, "replace", result); err != nil {
+		return time.Since(start), err
+	}
+	select {
+	case <-ctx.Done():
+		return time.Since(start), ctx.Err()
+	case status := <-result:
+		if status != "done" {
+			return time.Since(start), errors.Errorf("systemd job for %s finished with status %q", c.unit, status)
+		}
+	}
+	return time.Since(start), nil
+}
+
+// Start starts the unit and waits for the systemd job to finish
+func (c *SystemdController) Start(ctx context.Context) (time.Duration, error) {
+	return c.run(ctx, c.conn.StartUnitContext)
+}
+
+// Stop stops the unit and waits for the systemd job to finish
+func (c *SystemdController) Stop(ctx context.Context) (time.Duration, error) {
+	return c.run(ctx, c.conn.StopUnitContext)
+}
+
+// Restart restarts the unit and waits for the systemd job to finish
+func (c *SystemdController) Restart(ctx context.Context) (time.Duration, error) {
+	return c.run(ctx, c.conn.RestartUnitContext)
+}
+
+// Close releases the D-Bus connection
+func (c *SystemdController) Close() {
+	c.conn.Close()
+}